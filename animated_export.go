@@ -0,0 +1,174 @@
+package spriter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+)
+
+// canvasFrame returns the frame placed in the canvas shared by all the frames
+func (e *ExportedAnimation) canvasFrame(frame *ExportedFrame) *image.RGBA {
+	canvas := image.NewRGBA(image.Rectangle{Max: e.CanvasSize})
+	draw.Draw(canvas, frame.SourceRect, frame.Image, image.Point{}, draw.Src)
+	return canvas
+}
+
+// WriteGIF encodes the frames as an animated GIF. Colors are reduced to the web-safe palette,
+// pixels with less than 50% of opacity are transparent.
+func (e *ExportedAnimation) WriteGIF(w io.Writer) error {
+	colors := make(color.Palette, 0, len(palette.WebSafe)+1)
+	colors = append(colors, color.Transparent)
+	colors = append(colors, palette.WebSafe...)
+
+	animation := &gif.GIF{
+		Config: image.Config{ColorModel: colors, Width: e.CanvasSize.X, Height: e.CanvasSize.Y},
+	}
+	if !e.Looping {
+		animation.LoopCount = -1
+	}
+	for _, frame := range e.Frames {
+		img := e.canvasFrame(frame)
+		paletted := image.NewPaletted(img.Bounds(), colors)
+		for y := 0; y < e.CanvasSize.Y; y++ {
+			for x := 0; x < e.CanvasSize.X; x++ {
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				if c.A < 128 {
+					paletted.SetColorIndex(x, y, 0)
+					continue
+				}
+				c.A = 255
+				// The transparent color is skipped
+				paletted.SetColorIndex(x, y, uint8(colors[1:].Index(c)+1))
+			}
+		}
+		animation.Image = append(animation.Image, paletted)
+		// GIF delays are in hundredths of second
+		animation.Delay = append(animation.Delay, int(math.Round(float64(frame.Duration)/10)))
+		animation.Disposal = append(animation.Disposal, gif.DisposalBackground)
+	}
+	return gif.EncodeAll(w, animation)
+}
+
+// WriteAPNG encodes the frames as an animated PNG, with full colors and alpha
+func (e *ExportedAnimation) WriteAPNG(w io.Writer) error {
+	buffer := bufio.NewWriter(w)
+	if _, err := buffer.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return err
+	}
+	width, height := uint32(e.CanvasSize.X), uint32(e.CanvasSize.Y)
+
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], width)
+	binary.BigEndian.PutUint32(header[4:], height)
+	// 8 bits per channel, RGBA, deflate, adaptive filtering, no interlace
+	header[8], header[9] = 8, 6
+	writePNGChunk(buffer, "IHDR", header)
+
+	plays := uint32(0)
+	if !e.Looping {
+		plays = 1
+	}
+	control := make([]byte, 8)
+	binary.BigEndian.PutUint32(control[0:], uint32(len(e.Frames)))
+	binary.BigEndian.PutUint32(control[4:], plays)
+	writePNGChunk(buffer, "acTL", control)
+
+	sequence := uint32(0)
+	for i, frame := range e.Frames {
+		frameControl := make([]byte, 26)
+		binary.BigEndian.PutUint32(frameControl[0:], sequence)
+		binary.BigEndian.PutUint32(frameControl[4:], width)
+		binary.BigEndian.PutUint32(frameControl[8:], height)
+		// Delay as a fraction: duration/1000 seconds
+		binary.BigEndian.PutUint16(frameControl[20:], uint16(minInt(frame.Duration, math.MaxUint16)))
+		binary.BigEndian.PutUint16(frameControl[22:], 1000)
+		// Dispose to background, replace the previous content
+		frameControl[24], frameControl[25] = 1, 0
+		writePNGChunk(buffer, "fcTL", frameControl)
+		sequence++
+
+		data, err := compressImageData(e.canvasFrame(frame))
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			// The first frame is also the default image
+			writePNGChunk(buffer, "IDAT", data)
+		} else {
+			frameData := make([]byte, 4+len(data))
+			binary.BigEndian.PutUint32(frameData, sequence)
+			copy(frameData[4:], data)
+			writePNGChunk(buffer, "fdAT", frameData)
+			sequence++
+		}
+	}
+	writePNGChunk(buffer, "IEND", nil)
+	return buffer.Flush()
+}
+
+// compressImageData returns the zlib compressed, non premultiplied, RGBA rows of the image
+func compressImageData(img *image.RGBA) ([]byte, error) {
+	bounds := img.Bounds()
+	var data bytes.Buffer
+	compressor := zlib.NewWriter(&data)
+	row := make([]byte, 1+4*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		// Filter type None
+		row[0] = 0
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.RGBAAt(x, y)).(color.NRGBA)
+			offset := 1 + 4*(x-bounds.Min.X)
+			row[offset], row[offset+1], row[offset+2], row[offset+3] = c.R, c.G, c.B, c.A
+		}
+		if _, err := compressor.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := compressor.Close(); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+func writePNGChunk(w io.Writer, chunkType string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], chunkType)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+	w.Write(header)
+	w.Write(data)
+	w.Write(footer)
+}
+
+// ExportGIF renders an animation and writes it as an animated GIF
+func ExportGIF(entity *Entity, animationName string, options *ExportOptions, path string) error {
+	return exportAnimated(entity, animationName, options, path, (*ExportedAnimation).WriteGIF)
+}
+
+// ExportAPNG renders an animation and writes it as an animated PNG
+func ExportAPNG(entity *Entity, animationName string, options *ExportOptions, path string) error {
+	return exportAnimated(entity, animationName, options, path, (*ExportedAnimation).WriteAPNG)
+}
+
+func exportAnimated(entity *Entity, animationName string, options *ExportOptions, path string, write func(*ExportedAnimation, io.Writer) error) error {
+	exported, err := ExportAnimation(entity, animationName, options)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, func(w io.Writer) error {
+		return write(exported, w)
+	})
+}
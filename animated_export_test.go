@@ -0,0 +1,153 @@
+package spriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+// exportTestAnimations exports the looping walk and the non looping idle of the test model, at 10 fps
+func exportTestAnimations(t *testing.T) []*ExportedAnimation {
+	model := loadTestModel(t, testHeroSCML)
+	writeTestImages(t, model)
+	options := MakeExportOptions()
+	options.FrameRate = 10
+	var exported []*ExportedAnimation
+	for _, name := range []string{"walk", "idle"} {
+		animation, err := ExportAnimation(model.Entities[0], name, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exported = append(exported, animation)
+	}
+	return exported
+}
+
+func TestWriteGIF(t *testing.T) {
+	for _, exported := range exportTestAnimations(t) {
+		t.Run(exported.Animation, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := exported.WriteGIF(&buffer); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := gif.DecodeAll(&buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decoded.Image) != len(exported.Frames) {
+				t.Fatalf("expected %d frames, got %d", len(exported.Frames), len(decoded.Image))
+			}
+			for i, delay := range decoded.Delay {
+				// Hundredths of second
+				if delay != exported.Frames[i].Duration/10 {
+					t.Errorf("frame %d: expected a delay of %d, got %d", i, exported.Frames[i].Duration/10, delay)
+				}
+			}
+			// 0 loops forever, -1 plays once
+			loopCount := 0
+			if !exported.Looping {
+				loopCount = -1
+			}
+			if decoded.LoopCount != loopCount {
+				t.Errorf("expected the loop count %d, got %d", loopCount, decoded.LoopCount)
+			}
+			if decoded.Config.Width != exported.CanvasSize.X || decoded.Config.Height != exported.CanvasSize.Y {
+				t.Errorf("expected a %v canvas, got %dx%d", exported.CanvasSize, decoded.Config.Width, decoded.Config.Height)
+			}
+		})
+	}
+}
+
+type pngChunk struct {
+	chunkType string
+	data      []byte
+}
+
+func readPNGChunks(t *testing.T, data []byte) []pngChunk {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatal("missing PNG signature")
+	}
+	data = data[8:]
+	var chunks []pngChunk
+	for len(data) > 0 {
+		if len(data) < 12 {
+			t.Fatalf("truncated chunk after %d chunks", len(chunks))
+		}
+		length := int(binary.BigEndian.Uint32(data))
+		if len(data) < 12+length {
+			t.Fatalf("truncated chunk after %d chunks", len(chunks))
+		}
+		chunk := pngChunk{string(data[4:8]), data[8 : 8+length]}
+		if crc := binary.BigEndian.Uint32(data[8+length:]); crc != crc32.ChecksumIEEE(data[4:8+length]) {
+			t.Errorf("invalid checksum of chunk %d (%s)", len(chunks), chunk.chunkType)
+		}
+		chunks = append(chunks, chunk)
+		data = data[12+length:]
+	}
+	return chunks
+}
+
+func TestWriteAPNG(t *testing.T) {
+	for _, exported := range exportTestAnimations(t) {
+		t.Run(exported.Animation, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := exported.WriteAPNG(&buffer); err != nil {
+				t.Fatal(err)
+			}
+			// Decoders without APNG support show the first frame
+			if img, err := png.Decode(bytes.NewReader(buffer.Bytes())); err != nil {
+				t.Fatal(err)
+			} else if img.Bounds().Size() != exported.CanvasSize {
+				t.Errorf("expected a %v image, got %v", exported.CanvasSize, img.Bounds().Size())
+			}
+
+			chunks := readPNGChunks(t, buffer.Bytes())
+			var types []string
+			var sequence []uint32
+			frames := 0
+			for _, chunk := range chunks {
+				types = append(types, chunk.chunkType)
+				switch chunk.chunkType {
+				case "acTL":
+					plays := uint32(0)
+					if !exported.Looping {
+						plays = 1
+					}
+					if n := binary.BigEndian.Uint32(chunk.data); n != uint32(len(exported.Frames)) {
+						t.Errorf("expected %d frames in acTL, got %d", len(exported.Frames), n)
+					}
+					if n := binary.BigEndian.Uint32(chunk.data[4:]); n != plays {
+						t.Errorf("expected %d plays, got %d", plays, n)
+					}
+				case "fcTL":
+					sequence = append(sequence, binary.BigEndian.Uint32(chunk.data))
+					delay, unit := binary.BigEndian.Uint16(chunk.data[20:]), binary.BigEndian.Uint16(chunk.data[22:])
+					if int(delay)*1000/int(unit) != exported.Frames[frames].Duration {
+						t.Errorf("frame %d: expected %d ms, got %d/%d s", frames, exported.Frames[frames].Duration, delay, unit)
+					}
+					frames++
+				case "fdAT":
+					sequence = append(sequence, binary.BigEndian.Uint32(chunk.data))
+				}
+			}
+			if frames != len(exported.Frames) {
+				t.Errorf("expected %d fcTL chunks, got %d", len(exported.Frames), frames)
+			}
+			// fcTL and fdAT share the same sequence, without gaps
+			for i := range sequence {
+				if sequence[i] != uint32(i) {
+					t.Fatalf("expected the sequence numbers 0 to %d, got %v", len(sequence)-1, sequence)
+				}
+			}
+			// The first frame is the default image, the others are fdAT chunks
+			if len(types) < 5 || types[0] != "IHDR" || types[1] != "acTL" || types[2] != "fcTL" || types[3] != "IDAT" ||
+				types[len(types)-1] != "IEND" || len(sequence) != 2*len(exported.Frames)-1 {
+				t.Errorf("unexpected chunks %v", types)
+			}
+		})
+	}
+}
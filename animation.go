@@ -81,6 +81,18 @@ func (a *Animation) update(time int, root *TimelineKeyObject) {
 }
 
 func (a *Animation) updateObjectRef(ref *ObjectRef, root *TimelineKeyObject, time int) {
+	a.interpolateRef(ref, a.currentKey, time, a.interpolatedKeys[ref.Timeline].object)
+	a.unmappedInterpolatedKeys[ref.Timeline].active = true
+	refParent := root
+	if ref.ParentRef != nil {
+		refParent = a.unmappedInterpolatedKeys[ref.ParentRef.Timeline].object
+	}
+	a.unmapTimelineObject(ref.Timeline, refParent)
+}
+
+// interpolateRef interpolates the keys of the timeline of a ref at the given time, in the space of its parent.
+// mainlineKey is the key of the mainline containing the ref, its curve eases the interpolation.
+func (a *Animation) interpolateRef(ref *ObjectRef, mainlineKey *MainlineKey, time int, target *TimelineKeyObject) {
 	// Get the timelines, the refs pointing to
 	timeline := a.Timelines[ref.Timeline]
 	key := timeline.Keys[ref.Key]
@@ -110,31 +122,24 @@ func (a *Animation) updateObjectRef(ref *ObjectRef, root *TimelineKeyObject, tim
 	if math.IsNaN(t) || math.IsInf(t, 0) {
 		t = 1
 	}
-	if a.currentKey.Time > currentTime {
-		tMid := float64(a.currentKey.Time-currentTime) / float64(nextTime-currentTime)
+	if mainlineKey.Time > currentTime {
+		tMid := float64(mainlineKey.Time-currentTime) / float64(nextTime-currentTime)
 		if math.IsNaN(tMid) || math.IsInf(tMid, 0) {
 			tMid = 0
 		}
-		t = float64(time-a.currentKey.Time) / float64(nextTime-a.currentKey.Time)
+		t = float64(time-mainlineKey.Time) / float64(nextTime-mainlineKey.Time)
 		if math.IsNaN(t) || math.IsInf(t, 0) {
 			t = 1
 		}
-		t = a.currentKey.Curve().interpolate(tMid, 1, t)
+		t = mainlineKey.Curve().interpolate(tMid, 1, t)
 	} else {
-		t = a.currentKey.Curve().interpolate(0, 1, t)
+		t = mainlineKey.Curve().interpolate(0, 1, t)
 	}
 
 	bone1 := key.object
 	bone2 := nextKey.object
-	tweenTarget := a.interpolatedKeys[ref.Timeline].object
-	tweenTarget.objectType = bone1.objectType
-	a.interpolateObject(bone1, bone2, tweenTarget, t, key.Curve, key.Spin)
-	a.unmappedInterpolatedKeys[ref.Timeline].active = true
-	refParent := root
-	if ref.ParentRef != nil {
-		refParent = a.unmappedInterpolatedKeys[ref.ParentRef.Timeline].object
-	}
-	a.unmapTimelineObject(ref.Timeline, refParent)
+	target.objectType = bone1.objectType
+	a.interpolateObject(bone1, bone2, target, t, key.Curve, key.Spin)
 }
 
 func (a *Animation) unmapTimelineObject(timeline int, root *TimelineKeyObject) {
@@ -152,6 +157,6 @@ func (a *Animation) interpolateBone(bone1 *TimelineKeyObject, bone2 *TimelineKey
 
 func (a *Animation) interpolateObject(object1 *TimelineKeyObject, object2 *TimelineKeyObject, target *TimelineKeyObject, t float64, curve *Curve, spin int) {
 	a.interpolateBone(object1, object2, target, t, curve, spin)
-	target.Alpha = curve.interpolateAngle(object1.Alpha, object2.Alpha, t)
+	target.Alpha = curve.interpolate(object1.Alpha, object2.Alpha, t)
 	target.fileIndex = object1.fileIndex
 }
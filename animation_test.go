@@ -0,0 +1,97 @@
+package spriter
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// A bone moving linearly from (0, 0, 0°) to (100, 0, 90°), with the given curve on the first mainline key
+// and a second mainline key at 500
+func makeMainlineCurveTestModel(curve string) string {
+	return fmt.Sprintf(`<spriter_data scml_version="1.0">
+<entity id="0" name="e">
+	<animation id="0" name="a" length="1000" looping="false">
+		<mainline>
+			<key id="0" time="0" %s><bone_ref id="0" timeline="0" key="0"/></key>
+			<key id="1" time="500" %s><bone_ref id="0" timeline="0" key="0"/></key>
+		</mainline>
+		<timeline id="0" name="b" object_type="bone">
+			<key id="0" time="0"><bone x="0" y="0" angle="0"/></key>
+			<key id="1" time="1000"><bone x="100" y="0" angle="90"/></key>
+		</timeline>
+	</animation>
+</entity>
+</spriter_data>`, curve, curve)
+}
+
+var mainlineCurveTests = []struct {
+	name  string
+	curve string
+	// Eased progress at 250, in the middle of the first mainline key (a quarter of the timeline keys), and at 750,
+	// in the middle of the second one, which eases from halfway through the timeline keys
+	first  float64
+	second float64
+}{
+	{"linear", ``, 0.25, 0.75},
+	// Quadratic(0, 0.2, 1, 0.25) and Quadratic(0.5, 0.6, 1, 0.5)
+	{"quadratic", `curve_type="quadratic" c1="0.2"`, 0.1375, 0.675},
+	// Cubic(0, 0.1, 0.2, 1, 0.25) and Cubic(0.5, 0.55, 0.6, 1, 0.5)
+	{"cubic", `curve_type="cubic" c1="0.1" c2="0.2"`, 0.0859375, 0.61875},
+	// Bezier "ease-in": y is 0.0934646507188248 for x=0.25 and 0.3153568125725393 for x=0.5
+	{"bezier", `curve_type="bezier" c1="0.42" c2="0" c3="1" c4="1"`, 0.0934646507188248, 0.5 + 0.5*0.3153568125725393},
+}
+
+func checkMainlineCurve(t *testing.T, model *Model, first float64, second float64) {
+	t.Helper()
+	p := MakeEntityPlayer(model.Entities[0])
+	p.SetAnimationByName("a")
+	for _, sample := range []struct {
+		time     int
+		progress float64
+	}{{250, first}, {750, second}} {
+		p.setTime(sample.time)
+		p.Update(0)
+		bone := p.getBoneByName("b")
+		x, angle := 100*sample.progress, 90*sample.progress
+		if math.Abs(bone.Position.X()-x) > 1e-6 || math.Abs(bone.Angle*180/math.Pi-angle) > 1e-6 {
+			t.Errorf("at %d expected x=%g angle=%g, got x=%g angle=%g", sample.time, x, angle, bone.Position.X(), bone.Angle*180/math.Pi)
+		}
+	}
+}
+
+func TestMainlineCurves(t *testing.T) {
+	for _, test := range mainlineCurveTests {
+		t.Run(test.name, func(t *testing.T) {
+			checkMainlineCurve(t, loadTestModel(t, makeMainlineCurveTestModel(test.curve)), test.first, test.second)
+		})
+	}
+}
+
+func TestMainlineCurvesBinary(t *testing.T) {
+	for _, test := range mainlineCurveTests {
+		t.Run(test.name, func(t *testing.T) {
+			model := loadTestModel(t, makeMainlineCurveTestModel(test.curve))
+			var buffer bytes.Buffer
+			if err := EncodeBinaryModel(&buffer, model); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := DecodeBinaryModel(buffer.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, key := range decoded.Entities[0].Animations[0].Mainline.Keys {
+				original := model.Entities[0].Animations[0].Mainline.Keys[k]
+				if key.C1 != original.C1 || key.C2 != original.C2 || key.C3 != original.C3 || key.C4 != original.C4 {
+					t.Errorf("key %d: expected c1..c4 %g %g %g %g, got %g %g %g %g", k,
+						original.C1, original.C2, original.C3, original.C4, key.C1, key.C2, key.C3, key.C4)
+				}
+				if key.Curve().constraints != original.Curve().constraints || key.Curve().Name() != original.Curve().Name() {
+					t.Errorf("key %d: expected curve %s, got %s", k, original.Curve(), key.Curve())
+				}
+			}
+			checkMainlineCurve(t, decoded, test.first, test.second)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package spriter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// PosePlayer is the query and draw interface shared by EntityPlayer and BakedPlayer
+type PosePlayer interface {
+	Update(timeDeltaMs int)
+	GetNumObjectsToDraw() int
+	GetKeyObjectToDraw(index int) *TimelineKeyObject
+	GetDrawOrder() []int
+	GetMappedFileIndexForKeyObject(object *TimelineKeyObject) int
+	GetMappedFileForKeyObject(object *TimelineKeyObject) *File
+	GetAABB(flags BoundsFlags) *Rect
+	HitTest(x float64, y float64) *HitResult
+	GetDrawCommands() []DrawCommand
+	AppendDrawCommands(commands []DrawCommand) []DrawCommand
+	Draw(renderer Renderer)
+}
+
+var (
+	_ PosePlayer = (*EntityPlayer)(nil)
+	_ PosePlayer = (*BakedPlayer)(nil)
+)
+
+// Number of float32 values per timeline and frame: x, y, angle, scale x, scale y, alpha, pivot x, pivot y
+const bakedStride = 8
+
+// BakeError is the maximum difference between a baked animation and the interpolated one
+type BakeError struct {
+	Position float64
+	// In degrees
+	Angle float64
+	Scale float64
+	Alpha float64
+}
+
+func (e *BakeError) String() string {
+	return fmt.Sprintf("position: %g, angle: %g°, scale: %g, alpha: %g", e.Position, e.Angle, e.Scale, e.Alpha)
+}
+
+// BakeStats reports the cost and the accuracy of a bake. Errors are measured on the frames and half way
+// between them, playing with and without interpolation.
+type BakeStats struct {
+	Frames int
+	// Memory used by the table
+	Bytes        int
+	Nearest      BakeError
+	Interpolated BakeError
+}
+
+func (s *BakeStats) String() string {
+	return fmt.Sprintf("%d frames, %d bytes, error without interpolation [%s], with interpolation [%s]",
+		s.Frames, s.Bytes, s.Nearest.String(), s.Interpolated.String())
+}
+
+// BakedAnimation is an animation sampled at a fixed rate. For each frame it stores the transformations of the
+// timelines in the space of the entity, as if it was played at the origin with no rotation, scaling or flipping.
+type BakedAnimation struct {
+	Animation     *Animation
+	FrameDuration int
+	Frames        int
+	Stats         BakeStats
+
+	// Frames × timelines × bakedStride
+	transforms  []float32
+	fileIndices []int32
+	active      []bool
+	// The mainline key of each frame
+	keys []*MainlineKey
+}
+
+// BakeAnimation samples an animation of the entity at the given frame rate
+func BakeAnimation(entity *Entity, animationName string, frameRate float64) (*BakedAnimation, error) {
+	animation := entity.getAnimationByName(animationName)
+	if animation == nil {
+		return nil, fmt.Errorf("animation '%s' not found in entity '%s'", animationName, entity.Name)
+	}
+	if len(animation.Mainline.Keys) == 0 {
+		return nil, fmt.Errorf("animation '%s' has no mainline keys", animationName)
+	}
+	if frameRate <= 0 {
+		return nil, fmt.Errorf("invalid frame rate %g", frameRate)
+	}
+
+	b := &BakedAnimation{
+		Animation:     animation,
+		FrameDuration: int(math.Max(1, math.Round(1000/frameRate))),
+	}
+	b.Frames = (animation.Length+b.FrameDuration-1)/b.FrameDuration + 1
+	numTimelines := len(animation.Timelines)
+	b.transforms = make([]float32, b.Frames*numTimelines*bakedStride)
+	b.fileIndices = make([]int32, b.Frames*numTimelines)
+	b.active = make([]bool, b.Frames*numTimelines)
+	b.keys = make([]*MainlineKey, b.Frames)
+
+	player := MakeEntityPlayer(entity)
+	player.setAnimation(animation)
+	for frame := 0; frame < b.Frames; frame++ {
+		player.time = b.frameTime(frame)
+		player.Update(0)
+		b.keys[frame] = player.currentKey
+		for i := 0; i < numTimelines; i++ {
+			key := player.unmappedInterpolatedKeys[i]
+			index := frame*numTimelines + i
+			b.active[index] = key.active
+			b.fileIndices[index] = int32(key.object.fileIndex)
+			o := key.object
+			values := b.transforms[index*bakedStride : (index+1)*bakedStride]
+			values[0], values[1] = float32(o.Position.X()), float32(o.Position.Y())
+			values[2] = float32(o.Angle)
+			values[3], values[4] = float32(o.Scale.X()), float32(o.Scale.Y())
+			values[5] = float32(o.Alpha)
+			values[6], values[7] = float32(o.Pivot.X()), float32(o.Pivot.Y())
+		}
+	}
+
+	b.Stats.Frames = b.Frames
+	b.Stats.Bytes = len(b.transforms)*4 + len(b.fileIndices)*4 + len(b.active) + len(b.keys)*8
+	b.measureError(entity)
+	return b, nil
+}
+
+// BakeEntity samples all the animations of the entity
+func BakeEntity(entity *Entity, frameRate float64) ([]*BakedAnimation, error) {
+	baked := make([]*BakedAnimation, 0, len(entity.Animations))
+	for _, animation := range entity.Animations {
+		b, err := BakeAnimation(entity, animation.Name, frameRate)
+		if err != nil {
+			return nil, err
+		}
+		baked = append(baked, b)
+	}
+	return baked, nil
+}
+
+// frameTime returns the time of a frame. The last frame is always at the end of the animation.
+func (b *BakedAnimation) frameTime(frame int) int {
+	return minInt(frame*b.FrameDuration, b.Animation.Length)
+}
+
+// apply sets the pose of the player to the one at the given time
+func (b *BakedAnimation) apply(p *EntityPlayer, time int, interpolate bool) {
+	time = maxInt(0, minInt(time, b.Animation.Length))
+	frame := minInt(time/b.FrameDuration, b.Frames-1)
+	next := minInt(frame+1, b.Frames-1)
+	t := 0.0
+	if interpolate && next != frame {
+		t = float64(time-b.frameTime(frame)) / float64(b.frameTime(next)-b.frameTime(frame))
+	}
+
+	p.animation = b.Animation
+	p.currentKey = b.keys[frame]
+	numTimelines := len(b.Animation.Timelines)
+	for i := 0; i < numTimelines; i++ {
+		index := frame*numTimelines + i
+		key := p.unmappedInterpolatedKeys[i]
+		key.active = b.active[index]
+		if !key.active {
+			continue
+		}
+		values := b.transforms[index*bakedStride : (index+1)*bakedStride]
+		o := key.object
+		o.Position.SetCoords(float64(values[0]), float64(values[1]))
+		o.Angle = float64(values[2])
+		o.Scale.SetCoords(float64(values[3]), float64(values[4]))
+		o.Alpha = float64(values[5])
+		o.Pivot.SetCoords(float64(values[6]), float64(values[7]))
+
+		// Interpolates only between frames showing the same thing
+		nextIndex := next*numTimelines + i
+		if t > 0 && b.active[nextIndex] && b.fileIndices[nextIndex] == b.fileIndices[index] {
+			nextValues := b.transforms[nextIndex*bakedStride : (nextIndex+1)*bakedStride]
+			o.Position.SetCoords(Linear(o.Position.X(), float64(nextValues[0]), t), Linear(o.Position.Y(), float64(nextValues[1]), t))
+			o.Angle += math.Remainder(float64(nextValues[2])-o.Angle, 2*math.Pi) * t
+			o.Scale.SetCoords(Linear(o.Scale.X(), float64(nextValues[3]), t), Linear(o.Scale.Y(), float64(nextValues[4]), t))
+			o.Alpha = Linear(o.Alpha, float64(nextValues[5]), t)
+			o.Pivot.SetCoords(Linear(o.Pivot.X(), float64(nextValues[6]), t), Linear(o.Pivot.Y(), float64(nextValues[7]), t))
+		}
+
+		o.objectType = b.Animation.Timelines[i].ObjectType
+		o.fileIndex = int(b.fileIndices[index])
+		if o.fileIndex >= 0 {
+			o.Folder = o.fileIndex >> NumBitsPerFolder
+			o.File = o.fileIndex & (1<<NumBitsPerFolder - 1)
+		}
+		o.unmapCoordinates(p.root)
+	}
+}
+
+// measureError compares the baked animation with the interpolated one, on the frames and half way between them
+func (b *BakedAnimation) measureError(entity *Entity) {
+	reference := MakeEntityPlayer(entity)
+	reference.setAnimation(b.Animation)
+	baked := MakeEntityPlayer(entity)
+
+	for frame := 0; frame < b.Frames; frame++ {
+		times := []int{b.frameTime(frame)}
+		if frame+1 < b.Frames {
+			times = append(times, (b.frameTime(frame)+b.frameTime(frame+1))/2)
+		}
+		for _, time := range times {
+			reference.time = time
+			reference.Update(0)
+			b.apply(baked, time, false)
+			measurePoseError(reference, baked, &b.Stats.Nearest)
+			b.apply(baked, time, true)
+			measurePoseError(reference, baked, &b.Stats.Interpolated)
+		}
+	}
+}
+
+func measurePoseError(reference *EntityPlayer, baked *EntityPlayer, e *BakeError) {
+	for i := range reference.animation.Timelines {
+		a, b := reference.unmappedInterpolatedKeys[i], baked.unmappedInterpolatedKeys[i]
+		if !a.active || !b.active {
+			continue
+		}
+		objectA, objectB := a.object, b.object
+		e.Position = math.Max(e.Position, distance(objectA.Position, objectB.Position))
+		e.Angle = math.Max(e.Angle, math.Abs(math.Remainder(objectA.Angle-objectB.Angle, 2*math.Pi))*180/math.Pi)
+		e.Scale = math.Max(e.Scale, math.Max(math.Abs(objectA.Scale.X()-objectB.Scale.X()), math.Abs(objectA.Scale.Y()-objectB.Scale.Y())))
+		e.Alpha = math.Max(e.Alpha, math.Abs(objectA.Alpha-objectB.Alpha))
+	}
+}
+
+// ErrBakedPose is returned by the methods of BakedPlayer which need to resolve the bones
+var ErrBakedPose = errors.New("baked player: the bones of a baked pose can't be changed")
+
+// BakedPlayer plays baked animations. It doesn't interpolate keys nor resolve the hierarchy of the bones: the
+// embedded EntityPlayer holds the baked pose, with the options, swaps, queries and drawing of a regular player.
+// Overrides, IK, constraints and root motion return ErrBakedPose. SetBone and SetBoneAngle change the pose
+// only until the next Update.
+type BakedPlayer struct {
+	*EntityPlayer
+	animations  map[string]*BakedAnimation
+	baked       *BakedAnimation
+	time        int
+	interpolate bool
+}
+
+// MakeBakedPlayer creates a player for the baked animations of an entity. The first one is selected.
+func MakeBakedPlayer(entity *Entity, animations []*BakedAnimation) *BakedPlayer {
+	b := &BakedPlayer{
+		EntityPlayer: MakeEntityPlayer(entity),
+		animations:   make(map[string]*BakedAnimation),
+		interpolate:  true,
+	}
+	for _, animation := range animations {
+		b.animations[animation.Animation.Name] = animation
+	}
+	if len(animations) > 0 {
+		b.setAnimation(animations[0])
+	}
+	return b
+}
+
+func (b *BakedPlayer) setAnimation(animation *BakedAnimation) {
+	if animation == b.baked {
+		return
+	}
+	b.baked = animation
+	b.time = 0
+	b.Update(0)
+}
+
+// SetAnimationByName selects a baked animation. An error is returned if the animation has not been baked.
+func (b *BakedPlayer) SetAnimationByName(name string) error {
+	animation, ok := b.animations[name]
+	if !ok {
+		return fmt.Errorf("animation '%s' has not been baked", name)
+	}
+	b.setAnimation(animation)
+	return nil
+}
+
+func (b *BakedPlayer) GetAnimation() *Animation {
+	if b.baked == nil {
+		return nil
+	}
+	return b.baked.Animation
+}
+
+// SetInterpolation enables the linear interpolation between the baked frames. It's enabled by default.
+func (b *BakedPlayer) SetInterpolation(interpolate bool) *BakedPlayer {
+	b.interpolate = interpolate
+	return b
+}
+
+func (b *BakedPlayer) SetTime(time int) *BakedPlayer {
+	b.time = time
+	b.increaseTime(0)
+	return b
+}
+
+func (b *BakedPlayer) GetTime() int {
+	return b.time
+}
+
+func (b *BakedPlayer) Update(timeDeltaMs int) {
+	if b.baked == nil {
+		return
+	}
+	if b.EntityPlayer.rootIsDirty {
+		b.EntityPlayer.updateRoot()
+	}
+	b.baked.apply(b.EntityPlayer, b.time, b.interpolate)
+	b.increaseTime(timeDeltaMs)
+}
+
+func (b *BakedPlayer) increaseTime(millisecs int) {
+	if b.baked == nil {
+		return
+	}
+	length := b.baked.Animation.Length
+	b.time += millisecs
+	if length <= 0 {
+		b.time = 0
+		return
+	}
+	// A long update can loop several times, like with EntityPlayer
+	for b.time > length {
+		b.time -= length
+	}
+	for b.time < 0 {
+		b.time += length
+	}
+}
+
+// SetAnimationByIndex selects the baked animation with the index of an animation of the entity.
+// An error is returned if the animation has not been baked.
+func (b *BakedPlayer) SetAnimationByIndex(index int) error {
+	if index < 0 || index >= len(b.entity.Animations) {
+		return fmt.Errorf("animation %d not found in entity '%s'", index, b.entity.Name)
+	}
+	return b.SetAnimationByName(b.entity.Animations[index].Name)
+}
+
+func (b *BakedPlayer) AddIKChain(chain *IKChain) error {
+	return ErrBakedPose
+}
+
+func (b *BakedPlayer) AddConstraint(constraint *LookAtConstraint) error {
+	return ErrBakedPose
+}
+
+func (b *BakedPlayer) SetBoneOverride(name string, override *BoneOverride) error {
+	return ErrBakedPose
+}
+
+func (b *BakedPlayer) SetRootMotion(rootMotion *RootMotion) error {
+	return ErrBakedPose
+}
@@ -0,0 +1,97 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBakedPlayerOptions(t *testing.T) {
+	entity := loadTestModel(t, testHeroSCML).Entities[0]
+	baked, err := BakeEntity(entity, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := MakeBakedPlayer(entity, baked)
+	if err := b.SetAnimationByName("walk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetAnimationByName("run"); err == nil {
+		t.Error("expected an error selecting an animation which has not been baked")
+	}
+	p := MakeEntityPlayer(entity)
+	p.SetAnimationByName("walk")
+
+	// The options of the embedded player apply to the baked pose
+	b.SetPosition(100, 50).SetScale(2).HideObjectSprite("arm")
+	p.SetPosition(100, 50).SetScale(2).HideObjectSprite("arm")
+	b.Update(0)
+	p.Update(0)
+
+	expected, commands := p.GetDrawCommands(), b.GetDrawCommands()
+	if len(commands) != len(expected) {
+		t.Fatalf("expected %d draw commands, got %d", len(expected), len(commands))
+	}
+	for i := range commands {
+		if commands[i].Name == "arm" {
+			t.Errorf("arm is hidden and must not be drawn")
+		}
+		if commands[i].Name != expected[i].Name || commands[i].FileIndex != expected[i].FileIndex {
+			t.Errorf("expected %s drawing %d, got %s drawing %d",
+				expected[i].Name, expected[i].FileIndex, commands[i].Name, commands[i].FileIndex)
+		}
+		a, e := commands[i].Corners(), expected[i].Corners()
+		for j := range a {
+			if math.Abs(a[j].X()-e[j].X()) > 1e-3 || math.Abs(a[j].Y()-e[j].Y()) > 1e-3 {
+				t.Errorf("%s: expected corner %v, got %v", commands[i].Name, e[j], a[j])
+			}
+		}
+	}
+}
+
+func TestBakedPlayerLongUpdates(t *testing.T) {
+	entity := loadTestModel(t, testHeroSCML).Entities[0]
+	baked, err := BakeEntity(entity, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := MakeBakedPlayer(entity, baked)
+	p := MakeEntityPlayer(entity)
+	// Longer than the animation, forwards and backwards
+	for _, delta := range []int{2750, 1000, -3250, 10001} {
+		b.Update(delta)
+		p.Update(delta)
+		if b.GetTime() != p.getTime() {
+			t.Fatalf("after %d ms, expected the time %d, got %d", delta, p.getTime(), b.GetTime())
+		}
+	}
+}
+
+func TestBakedPlayerBones(t *testing.T) {
+	entity := loadTestModel(t, testHeroSCML).Entities[0]
+	baked, err := BakeEntity(entity, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := MakeBakedPlayer(entity, baked)
+	for name, err := range map[string]error{
+		"ik":          b.AddIKChain(MakeIKChain("arm", IKTwoBone, "upper", "lower")),
+		"constraint":  b.AddConstraint(&LookAtConstraint{Name: "look", Bone: "upper"}),
+		"override":    b.SetBoneOverride("upper", &BoneOverride{}),
+		"root motion": b.SetRootMotion(&RootMotion{Bone: "root"}),
+	} {
+		if err != ErrBakedPose {
+			t.Errorf("%s: expected ErrBakedPose, got %v", name, err)
+		}
+	}
+
+	if err := b.SetAnimationByIndex(1); err != nil || b.GetAnimation() != entity.Animations[1] {
+		t.Errorf("expected the second animation, got %v", err)
+	}
+	if err := b.SetAnimationByIndex(2); err == nil {
+		t.Error("expected an error for an animation which doesn't exist")
+	}
+	b.Update(0)
+	if b.EntityPlayer.GetAnimation() != b.GetAnimation() {
+		t.Error("the embedded player must show the baked animation")
+	}
+}
@@ -0,0 +1,491 @@
+package spriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// The binary format stores an initialized model, so that it can be played without parsing XML.
+// Layout: magic, version (uint16), payload length (uint32), CRC-32 of the payload (uint32), payload.
+// Integers in the payload are varints, floats are 64 bits and strings are prefixed by their length.
+const (
+	binaryMagic   = "SPRB"
+	BinaryVersion = 2
+)
+
+var errBinaryTruncated = errors.New("binary model: unexpected end of data")
+
+// IsBinaryModel tells if the data starts like a model in the binary format
+func IsBinaryModel(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(binaryMagic))
+}
+
+// EncodeBinaryModel writes an initialized model in the binary format. Angles are kept in radians,
+// curves with their resolved types, parents as indices of the resolved refs.
+func EncodeBinaryModel(w io.Writer, model *Model) error {
+	payload := &binaryWriter{}
+	payload.writeModel(model)
+
+	header := make([]byte, len(binaryMagic)+10)
+	copy(header, binaryMagic)
+	binary.LittleEndian.PutUint16(header[4:], BinaryVersion)
+	binary.LittleEndian.PutUint32(header[6:], uint32(payload.buffer.Len()))
+	binary.LittleEndian.PutUint32(header[10:], crc32.ChecksumIEEE(payload.buffer.Bytes()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload.buffer.Bytes())
+	return err
+}
+
+// DecodeBinaryModel reads a model written by EncodeBinaryModel. The data is rejected if the version
+// is not supported or the checksum doesn't match.
+func DecodeBinaryModel(data []byte) (*Model, error) {
+	if !IsBinaryModel(data) {
+		return nil, errors.New("binary model: invalid header")
+	}
+	if len(data) < len(binaryMagic)+10 {
+		return nil, errBinaryTruncated
+	}
+	version := binary.LittleEndian.Uint16(data[4:])
+	// Version 2 added the names of the custom curves, version 1 files never contain them
+	if version < 1 || version > BinaryVersion {
+		return nil, fmt.Errorf("binary model: unsupported version %d", version)
+	}
+	length := binary.LittleEndian.Uint32(data[6:])
+	checksum := binary.LittleEndian.Uint32(data[10:])
+	payload := data[len(binaryMagic)+10:]
+	if uint32(len(payload)) != length {
+		return nil, fmt.Errorf("binary model: expected %d bytes of data, found %d", length, len(payload))
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, errors.New("binary model: checksum mismatch")
+	}
+
+	r := &binaryReader{data: payload}
+	model := r.readModel()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return model, nil
+}
+
+type binaryWriter struct {
+	buffer  bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *binaryWriter) writeInt(value int) {
+	n := binary.PutVarint(w.scratch[:], int64(value))
+	w.buffer.Write(w.scratch[:n])
+}
+
+func (w *binaryWriter) writeFloat(value float64) {
+	binary.LittleEndian.PutUint64(w.scratch[:], math.Float64bits(value))
+	w.buffer.Write(w.scratch[:8])
+}
+
+func (w *binaryWriter) writeBool(value bool) {
+	if value {
+		w.buffer.WriteByte(1)
+	} else {
+		w.buffer.WriteByte(0)
+	}
+}
+
+func (w *binaryWriter) writeString(value string) {
+	w.writeInt(len(value))
+	w.buffer.WriteString(value)
+}
+
+// writeOptionalInt writes a flag followed, if the value is present, by the value
+func (w *binaryWriter) writeOptionalInt(value *int) {
+	w.writeBool(value != nil)
+	if value != nil {
+		w.writeInt(*value)
+	}
+}
+
+func (w *binaryWriter) writeCurve(c *Curve) {
+	w.writeInt(int(c.curveType))
+	if c.curveType == TypeCustom {
+		w.writeString(c.name)
+	}
+	for i := range c.constraints {
+		w.writeFloat(c.constraints[i])
+	}
+}
+
+func (w *binaryWriter) writeObjectInfo(info *ObjectInfo) {
+	w.writeString(info.Name)
+	w.writeString(string(info.Type))
+	w.writeFloat(info.Width)
+	w.writeFloat(info.Height)
+}
+
+func (w *binaryWriter) writeModel(m *Model) {
+	w.writeString(m.SconVersion)
+	w.writeString(m.Generator)
+	w.writeString(m.GeneratorVersion)
+
+	w.writeInt(len(m.Folders))
+	for _, folder := range m.Folders {
+		w.writeInt(folder.Id)
+		w.writeString(folder.Name)
+		w.writeInt(len(folder.Files))
+		for _, file := range folder.Files {
+			w.writeInt(file.Id)
+			w.writeString(file.Name)
+			w.writeInt(file.Width)
+			w.writeInt(file.Height)
+			w.writeFloat(file.PivotX)
+			w.writeFloat(file.PivotY)
+		}
+	}
+
+	w.writeInt(len(m.Entities))
+	for _, entity := range m.Entities {
+		w.writeInt(entity.Id)
+		w.writeString(entity.Name)
+		w.writeInt(entity.MaxNumTimelines)
+		w.writeInt(len(entity.ObjectInfos))
+		for _, info := range entity.ObjectInfos {
+			w.writeObjectInfo(info)
+		}
+		w.writeInt(len(entity.CharacterMaps))
+		for _, characterMap := range entity.CharacterMaps {
+			w.writeInt(characterMap.Id)
+			w.writeString(characterMap.Name)
+			w.writeInt(len(characterMap.Maps))
+			for _, mapping := range characterMap.Maps {
+				w.writeInt(mapping.Folder)
+				w.writeInt(mapping.File)
+				w.writeOptionalInt(mapping.TargetFolder)
+				w.writeOptionalInt(mapping.TargetFile)
+			}
+		}
+		w.writeInt(len(entity.Animations))
+		for _, animation := range entity.Animations {
+			w.writeAnimation(entity, animation)
+		}
+	}
+}
+
+func (w *binaryWriter) writeAnimation(entity *Entity, a *Animation) {
+	w.writeInt(a.Id)
+	w.writeString(a.Name)
+	w.writeInt(a.Length)
+	w.writeInt(a.Interval)
+	w.writeBool(a.Looping)
+
+	w.writeInt(len(a.Mainline.Keys))
+	for _, key := range a.Mainline.Keys {
+		w.writeInt(key.Id)
+		w.writeInt(key.Time)
+		w.writeCurve(key.Curve())
+		for _, refs := range [][]*ObjectRef{key.BoneRefs, key.ObjectRefs} {
+			w.writeInt(len(refs))
+			for _, ref := range refs {
+				w.writeInt(ref.Id)
+				w.writeInt(ref.Key)
+				w.writeInt(ref.Timeline)
+				w.writeString(ref.ZIndex)
+				w.writeOptionalInt(ref.Parent)
+				parentIndex := -1
+				for i := range key.BoneRefs {
+					if key.BoneRefs[i] == ref.ParentRef {
+						parentIndex = i
+					}
+				}
+				w.writeInt(parentIndex)
+			}
+		}
+	}
+
+	w.writeInt(len(a.Timelines))
+	for _, timeline := range a.Timelines {
+		w.writeInt(timeline.Id)
+		w.writeString(timeline.Name)
+		w.writeString(string(timeline.ObjectType))
+		// The info is shared with the entity, unless the loader made one for the timeline
+		infoIndex := -1
+		for i := range entity.ObjectInfos {
+			if entity.ObjectInfos[i] == timeline.objectInfo {
+				infoIndex = i
+			}
+		}
+		w.writeInt(infoIndex)
+		if infoIndex < 0 {
+			w.writeObjectInfo(timeline.objectInfo)
+		}
+
+		w.writeInt(len(timeline.Keys))
+		for _, key := range timeline.Keys {
+			w.writeInt(key.Id)
+			w.writeInt(key.Time)
+			w.writeInt(key.Spin)
+			w.writeCurve(key.Curve)
+			w.writeKeyObject(key.object)
+		}
+	}
+}
+
+func (w *binaryWriter) writeKeyObject(o *TimelineKeyObject) {
+	w.writeString(string(o.objectType))
+	w.writeInt(o.Folder)
+	w.writeInt(o.File)
+	w.writeInt(o.fileIndex)
+	w.writeFloat(o.Position.X())
+	w.writeFloat(o.Position.Y())
+	w.writeFloat(o.Angle)
+	w.writeFloat(o.Scale.X())
+	w.writeFloat(o.Scale.Y())
+	w.writeFloat(o.Alpha)
+	w.writeFloat(o.Pivot.X())
+	w.writeFloat(o.Pivot.Y())
+	// Keys with their own pivot, as opposed to the one of the file
+	w.writeBool(o.XMLPivotX != nil || o.XMLPivotY != nil)
+}
+
+type binaryReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *binaryReader) readInt() int {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(r.data[r.offset:])
+	if n <= 0 {
+		r.err = errBinaryTruncated
+		return 0
+	}
+	r.offset += n
+	return int(value)
+}
+
+// readCount reads the length of a list, making sure that the data can contain it
+func (r *binaryReader) readCount() int {
+	count := r.readInt()
+	if count < 0 || count > len(r.data)-r.offset {
+		if r.err == nil {
+			r.err = fmt.Errorf("binary model: invalid count %d at offset %d", count, r.offset)
+		}
+		return 0
+	}
+	return count
+}
+
+func (r *binaryReader) readFloat() float64 {
+	if r.err != nil {
+		return 0
+	}
+	if r.offset+8 > len(r.data) {
+		r.err = errBinaryTruncated
+		return 0
+	}
+	value := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.offset:]))
+	r.offset += 8
+	return value
+}
+
+func (r *binaryReader) readBool() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.offset >= len(r.data) {
+		r.err = errBinaryTruncated
+		return false
+	}
+	value := r.data[r.offset] != 0
+	r.offset++
+	return value
+}
+
+func (r *binaryReader) readString() string {
+	length := r.readCount()
+	if r.err != nil {
+		return ""
+	}
+	value := string(r.data[r.offset : r.offset+length])
+	r.offset += length
+	return value
+}
+
+func (r *binaryReader) readOptionalInt() *int {
+	if !r.readBool() {
+		return nil
+	}
+	value := r.readInt()
+	return &value
+}
+
+func (r *binaryReader) readCurve() *Curve {
+	c := MakeCurveWithType(CurveType(r.readInt()))
+	if c.curveType == TypeCustom {
+		// Easings which are not registered play as linear and keep their name, like in the SCML loader
+		c = MakeCurveWithName(r.readString())
+	}
+	for i := range c.constraints {
+		c.constraints[i] = r.readFloat()
+	}
+	return c
+}
+
+func (r *binaryReader) readObjectInfo() *ObjectInfo {
+	name := r.readString()
+	objectType := ObjectType(r.readString())
+	width := r.readFloat()
+	height := r.readFloat()
+	return MakeObjectInfo(name, objectType, width, height)
+}
+
+func (r *binaryReader) readModel() *Model {
+	m := &Model{}
+	m.SconVersion = r.readString()
+	m.Generator = r.readString()
+	m.GeneratorVersion = r.readString()
+
+	m.Files = make(map[int]*File)
+	m.Folders = make([]*Folder, r.readCount())
+	for i := range m.Folders {
+		folder := &Folder{Id: r.readInt(), Name: r.readString()}
+		folder.Files = make([]*File, r.readCount())
+		for j := range folder.Files {
+			file := &File{Id: r.readInt(), Name: r.readString()}
+			file.Width = r.readInt()
+			file.Height = r.readInt()
+			file.PivotX = r.readFloat()
+			file.PivotY = r.readFloat()
+			folder.Files[j] = file
+			m.Files[FolderAndFileToFileIndex(i, j)] = file
+		}
+		m.Folders[i] = folder
+	}
+
+	m.Entities = make([]*Entity, r.readCount())
+	for i := range m.Entities {
+		entity := &Entity{Id: r.readInt(), Name: r.readString(), model: m}
+		entity.MaxNumTimelines = r.readInt()
+		entity.ObjectInfos = make([]*ObjectInfo, r.readCount())
+		for j := range entity.ObjectInfos {
+			entity.ObjectInfos[j] = r.readObjectInfo()
+		}
+		entity.CharacterMaps = make([]*CharacterMap, r.readCount())
+		for j := range entity.CharacterMaps {
+			characterMap := &CharacterMap{Id: r.readInt(), Name: r.readString(), FilesMapping: make(map[int]int)}
+			characterMap.Maps = make([]mapInstructionData, r.readCount())
+			for k := range characterMap.Maps {
+				mapping := &characterMap.Maps[k]
+				mapping.Folder = r.readInt()
+				mapping.File = r.readInt()
+				mapping.TargetFolder = r.readOptionalInt()
+				mapping.TargetFile = r.readOptionalInt()
+				if mapping.TargetFile == nil || mapping.TargetFolder == nil {
+					characterMap.FilesMapping[FolderAndFileToFileIndex(mapping.Folder, mapping.File)] = -1
+				} else {
+					characterMap.FilesMapping[FolderAndFileToFileIndex(mapping.Folder, mapping.File)] = FolderAndFileToFileIndex(*mapping.TargetFolder, *mapping.TargetFile)
+				}
+			}
+			entity.CharacterMaps[j] = characterMap
+		}
+		entity.Animations = make([]*Animation, r.readCount())
+		for j := range entity.Animations {
+			entity.Animations[j] = r.readAnimation(entity)
+		}
+		m.Entities[i] = entity
+	}
+	return m
+}
+
+func (r *binaryReader) readAnimation(entity *Entity) *Animation {
+	a := &Animation{Id: r.readInt(), Name: r.readString()}
+	a.Length = r.readInt()
+	a.Interval = r.readInt()
+	a.Looping = r.readBool()
+
+	a.Mainline = &Mainline{Keys: make([]*MainlineKey, r.readCount())}
+	for i := range a.Mainline.Keys {
+		key := &MainlineKey{Id: r.readInt(), Time: r.readInt()}
+		key.curve = r.readCurve()
+		key.C1, key.C2, key.C3, key.C4 = key.curve.constraints[0], key.curve.constraints[1], key.curve.constraints[2], key.curve.constraints[3]
+		if key.curve.curveType != TypeLinear {
+			curveType := key.curve.Name()
+			key.CurveType = &curveType
+		}
+		for _, refs := range []*[]*ObjectRef{&key.BoneRefs, &key.ObjectRefs} {
+			*refs = make([]*ObjectRef, r.readCount())
+			parents := make([]int, len(*refs))
+			for j := range *refs {
+				ref := &ObjectRef{Id: r.readInt(), Key: r.readInt(), Timeline: r.readInt()}
+				ref.ZIndex = r.readString()
+				ref.Parent = r.readOptionalInt()
+				parents[j] = r.readInt()
+				(*refs)[j] = ref
+			}
+			// Bone refs come first, and a parent can come after its child in the file
+			for j, parentIndex := range parents {
+				if parentIndex >= 0 && parentIndex < len(key.BoneRefs) {
+					(*refs)[j].ParentRef = key.BoneRefs[parentIndex]
+				}
+			}
+		}
+		key.initializeBoneOrder()
+		a.Mainline.Keys[i] = key
+	}
+
+	a.Timelines = make([]*Timeline, r.readCount())
+	for i := range a.Timelines {
+		timeline := &Timeline{Id: r.readInt(), Name: r.readString()}
+		timeline.ObjectType = ObjectType(r.readString())
+		infoIndex := r.readInt()
+		if infoIndex >= 0 && infoIndex < len(entity.ObjectInfos) {
+			timeline.objectInfo = entity.ObjectInfos[infoIndex]
+		} else {
+			timeline.objectInfo = r.readObjectInfo()
+		}
+
+		timeline.Keys = make([]*TimelineKey, r.readCount())
+		for j := range timeline.Keys {
+			key := MakeTimelineKey(r.readInt())
+			key.Time = r.readInt()
+			key.Spin = r.readInt()
+			key.Curve = r.readCurve()
+			key.CurveType = key.Curve.Name()
+			key.C1, key.C2, key.C3, key.C4 = key.Curve.constraints[0], key.Curve.constraints[1], key.Curve.constraints[2], key.Curve.constraints[3]
+			key.object = r.readKeyObject()
+			timeline.Keys[j] = key
+		}
+		a.Timelines[i] = timeline
+	}
+	if r.err == nil {
+		a.initialize()
+	}
+	return a
+}
+
+func (r *binaryReader) readKeyObject() *TimelineKeyObject {
+	o := MakeTimelineKeyObject()
+	o.objectType = ObjectType(r.readString())
+	o.Folder = r.readInt()
+	o.File = r.readInt()
+	o.fileIndex = r.readInt()
+	o.Position.SetCoords(r.readFloat(), r.readFloat())
+	o.Angle = r.readFloat()
+	o.Scale.SetCoords(r.readFloat(), r.readFloat())
+	o.Alpha = r.readFloat()
+	o.Pivot.SetCoords(r.readFloat(), r.readFloat())
+	if r.readBool() {
+		pivotX, pivotY := o.Pivot.X(), o.Pivot.Y()
+		o.XMLPivotX, o.XMLPivotY = &pivotX, &pivotY
+	}
+	o.XMLX, o.XMLY = o.Position.X(), o.Position.Y()
+	return o
+}
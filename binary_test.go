@@ -0,0 +1,219 @@
+package spriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strings"
+	"testing"
+)
+
+func encodeTestBinaryModel(t testing.TB, model *Model) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := EncodeBinaryModel(&buffer, model); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// setBinaryPayload replaces the payload of a binary model, updating its length and checksum
+func setBinaryPayload(data []byte, payload []byte) []byte {
+	header := append([]byte{}, data[:len(binaryMagic)+10]...)
+	binary.LittleEndian.PutUint32(header[6:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[10:], crc32.ChecksumIEEE(payload))
+	return append(header, payload...)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	model := loadTestModel(t, testHeroSCML)
+	data := encodeTestBinaryModel(t, model)
+	if !IsBinaryModel(data) {
+		t.Fatal("the encoded data is not recognized as a binary model")
+	}
+	decoded, err := DecodeBinaryModel(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if differences := ComparePoses(model, decoded, 60, 0); len(differences) > 0 {
+		t.Fatalf("the poses changed: %s", differences[0].String())
+	}
+	// Encoding the decoded model gives the same data
+	if again := encodeTestBinaryModel(t, decoded); !bytes.Equal(data, again) {
+		t.Fatal("the decoded model is encoded differently")
+	}
+	var original, roundTrip bytes.Buffer
+	if err := EncodeModel(&original, model, FormatSCML); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncodeModel(&roundTrip, decoded, FormatSCML); err != nil {
+		t.Fatal(err)
+	}
+	if original.String() != roundTrip.String() {
+		t.Fatal("the decoded model is written differently as SCML")
+	}
+}
+
+func TestBinaryIntegrity(t *testing.T) {
+	data := encodeTestBinaryModel(t, loadTestModel(t, testHeroSCML))
+	payload := data[len(binaryMagic)+10:]
+	withVersion := func(version uint16) []byte {
+		changed := append([]byte{}, data...)
+		binary.LittleEndian.PutUint16(changed[4:], version)
+		return changed
+	}
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+
+	tests := []struct {
+		name string
+		data []byte
+		// Expected in the error, empty if the data is valid
+		err string
+	}{
+		{"valid", data, ""},
+		// Version 1 has the same layout as long as there are no custom curves
+		{"version 1", withVersion(1), ""},
+		{"version 0", withVersion(0), "unsupported version 0"},
+		{"unknown version", withVersion(BinaryVersion + 1), fmt.Sprintf("unsupported version %d", BinaryVersion+1)},
+		{"checksum mismatch", corrupted, "checksum mismatch"},
+		{"invalid header", []byte("SPRX"), "invalid header"},
+		{"truncated header", data[:len(binaryMagic)+4], "unexpected end of data"},
+		{"truncated stream", data[:len(data)-10], "expected"},
+		// The header matches the payload, but the payload ends in the middle of the model
+		{"truncated payload", setBinaryPayload(data, payload[:len(payload)/2]), "binary model:"},
+		{"empty payload", setBinaryPayload(data, nil), "unexpected end of data"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model, err := DecodeBinaryModel(test.data)
+			switch {
+			case test.err == "" && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case test.err == "" && model == nil:
+				t.Fatal("no model")
+			case test.err != "" && err == nil:
+				t.Fatalf("expected an error containing '%s'", test.err)
+			case test.err != "" && !strings.Contains(err.Error(), test.err):
+				t.Fatalf("expected an error containing '%s', got '%s'", test.err, err)
+			}
+		})
+	}
+}
+
+func TestBinaryCustomCurves(t *testing.T) {
+	// Curves registered with RegisterEasing are stored by name, since version 2
+	model := loadTestModel(t, testHeroSCML)
+	decoded, err := DecodeBinaryModel(encodeTestBinaryModel(t, model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	curve := decoded.Entities[0].Animations[0].Timelines[1].Keys[1].Curve
+	if curve.GetType() != TypeCustom || curve.Name() != "bounce" {
+		t.Fatalf("expected the bounce easing, got %s", curve)
+	}
+}
+
+func TestBinaryUnregisteredCurves(t *testing.T) {
+	// The easing is registered only after the first decoding: its name must survive the round trip
+	name := makeTestEasingName("binary-test-hold")
+	scml := strings.Replace(testHeroSCML, `<key id="0"><bone x="0" y="0" angle="90"/>`, `<key id="0" curve_type="`+name+`"><bone x="0" y="0" angle="90"/>`, 1)
+	scml = strings.Replace(scml, `<mainline>
+                <key id="0">`, `<mainline>
+                <key id="0" curve_type="`+name+`">`, 1)
+	decoded, err := DecodeBinaryModel(encodeTestBinaryModel(t, loadTestModel(t, scml)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	walk := decoded.Entities[0].Animations[0]
+	for _, curve := range []*Curve{walk.Timelines[0].Keys[0].Curve, walk.Mainline.Keys[0].curve} {
+		if curve.Name() != name {
+			t.Fatalf("expected the name of the unregistered easing, got %s", curve)
+		}
+	}
+	p := MakeEntityPlayer(decoded.Entities[0])
+	p.Update(250)
+	p.Update(0)
+	if y := p.getBoneByName("root").Position.Y(); math.Abs(y+50) > 1e-9 {
+		t.Errorf("unregistered easings must play as linear, got y %g", y)
+	}
+
+	var scmlBuffer bytes.Buffer
+	if err := EncodeModel(&scmlBuffer, decoded, FormatSCML); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(scmlBuffer.String(), `curve_type="`+name+`"`) != 2 {
+		t.Errorf("expected the unregistered curves in the SCML:\n%s", scmlBuffer.String())
+	}
+
+	data := encodeTestBinaryModel(t, decoded)
+	if err := RegisterEasing(name, EasingFunc(func(t float64, c [4]float64) float64 { return 0 })); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err = DecodeBinaryModel(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = MakeEntityPlayer(decoded.Entities[0])
+	p.Update(250)
+	p.Update(0)
+	if y := p.getBoneByName("root").Position.Y(); y != 0 {
+		t.Errorf("expected the registered easing to hold the root, got y %g", y)
+	}
+}
+
+// makeBenchmarkSCML returns a model with an animation of `timelines` sprites, each one with `keys` keys
+func makeBenchmarkSCML(timelines int, keys int) []byte {
+	var b strings.Builder
+	b.WriteString(`<spriter_data scml_version="1.0"><folder id="0" name="parts">`)
+	for i := 0; i < timelines; i++ {
+		fmt.Fprintf(&b, `<file id="%d" name="parts/%d.png" width="32" height="32" pivot_x="0.5" pivot_y="0.5"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `</folder><entity id="0" name="e"><animation id="0" name="a" length="%d"><mainline>`, keys*100)
+	for k := 0; k < keys; k++ {
+		fmt.Fprintf(&b, `<key id="%d" time="%d">`, k, k*100)
+		for i := 0; i < timelines; i++ {
+			fmt.Fprintf(&b, `<object_ref id="%d" timeline="%d" key="%d" z_index="%d"/>`, i, i, k, i)
+		}
+		b.WriteString(`</key>`)
+	}
+	b.WriteString(`</mainline>`)
+	for i := 0; i < timelines; i++ {
+		fmt.Fprintf(&b, `<timeline id="%d" name="t%d">`, i, i)
+		for k := 0; k < keys; k++ {
+			fmt.Fprintf(&b, `<key id="%d" time="%d" curve_type="cubic" c1="0.1" c2="0.9"><object folder="0" file="%d" x="%d" y="%d" angle="%d" scale_x="1.5" a="0.9"/></key>`,
+				k, k*100, i, i*10+k, k*5, k*7%360)
+		}
+		b.WriteString(`</timeline>`)
+	}
+	b.WriteString(`</animation></entity></spriter_data>`)
+	return []byte(b.String())
+}
+
+func BenchmarkLoadSCML(b *testing.B) {
+	data := makeBenchmarkSCML(30, 50)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeModel(data, FormatSCML); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBinary(b *testing.B) {
+	model, err := DecodeModel(makeBenchmarkSCML(30, 50), FormatSCML)
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := encodeTestBinaryModel(b, model)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeBinaryModel(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
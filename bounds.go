@@ -0,0 +1,290 @@
+package spriter
+
+import "math"
+
+// BoundsFlags selects which kind of objects contribute to the bounds of a player
+type BoundsFlags int
+
+const (
+	BoundsSprites BoundsFlags = 1 << iota
+	BoundsBoxes
+	BoundsPoints
+	BoundsAll = BoundsSprites | BoundsBoxes | BoundsPoints
+)
+
+// Rect is an axis-aligned rectangle. An empty rectangle has Min greater than Max.
+type Rect struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+func MakeEmptyRect() *Rect {
+	return &Rect{
+		MinX: math.Inf(1),
+		MinY: math.Inf(1),
+		MaxX: math.Inf(-1),
+		MaxY: math.Inf(-1),
+	}
+}
+
+func (r *Rect) IsEmpty() bool {
+	return r.MinX > r.MaxX || r.MinY > r.MaxY
+}
+
+func (r *Rect) Width() float64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.MaxX - r.MinX
+}
+
+func (r *Rect) Height() float64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.MaxY - r.MinY
+}
+
+func (r *Rect) AddPoint(x float64, y float64) *Rect {
+	r.MinX = math.Min(r.MinX, x)
+	r.MinY = math.Min(r.MinY, y)
+	r.MaxX = math.Max(r.MaxX, x)
+	r.MaxY = math.Max(r.MaxY, y)
+	return r
+}
+
+func (r *Rect) Union(other *Rect) *Rect {
+	if other.IsEmpty() {
+		return r
+	}
+	r.AddPoint(other.MinX, other.MinY)
+	r.AddPoint(other.MaxX, other.MaxY)
+	return r
+}
+
+func (r *Rect) Contains(x float64, y float64) bool {
+	return x >= r.MinX && x <= r.MaxX && y >= r.MinY && y <= r.MaxY
+}
+
+func (r *Rect) Corners() [4]Point {
+	return [4]Point{
+		{r.MinX, r.MinY},
+		{r.MaxX, r.MinY},
+		{r.MaxX, r.MaxY},
+		{r.MinX, r.MaxY},
+	}
+}
+
+// OrientedRect is a rectangle rotated by Angle (radians) around its center
+type OrientedRect struct {
+	Center     *Point
+	HalfWidth  float64
+	HalfHeight float64
+	Angle      float64
+}
+
+func (r *OrientedRect) Corners() [4]Point {
+	corners := [4]Point{
+		{-r.HalfWidth, -r.HalfHeight},
+		{r.HalfWidth, -r.HalfHeight},
+		{r.HalfWidth, r.HalfHeight},
+		{-r.HalfWidth, r.HalfHeight},
+	}
+	for i := range corners {
+		corners[i].Rotate(r.Angle)
+		corners[i].Add(r.Center)
+	}
+	return corners
+}
+
+// getObjectCorners returns the corners of the rectangle covered by a sprite or a box, in world coordinates.
+// Points have no area, all the corners are on the position of the point.
+func (p *EntityPlayer) getObjectCorners(object *TimelineKeyObject) ([4]Point, bool) {
+	var width, height float64
+	switch object.objectType {
+	case TypeSprite:
+		file := p.GetMappedFileForKeyObject(object)
+		if file == nil {
+			return [4]Point{}, false
+		}
+		width, height = float64(file.Width), float64(file.Height)
+	case TypeBox:
+		info := p.getObjectInfoFor(object)
+		width, height = info.Width, info.Height
+	case TypePoint:
+		position := *object.Position
+		return [4]Point{position, position, position, position}, true
+	default:
+		return [4]Point{}, false
+	}
+
+	left := -object.Pivot.X() * width
+	bottom := -object.Pivot.Y() * height
+	corners := [4]Point{
+		{left, bottom},
+		{left + width, bottom},
+		{left + width, bottom + height},
+		{left, bottom + height},
+	}
+	for i := range corners {
+		corners[i].Scale(object.Scale)
+		corners[i].Rotate(object.Angle)
+		corners[i].Add(object.Position)
+	}
+	return corners, true
+}
+
+func (p *EntityPlayer) boundsContain(object *TimelineKeyObject, flags BoundsFlags) bool {
+	switch object.objectType {
+	case TypeSprite:
+		return flags&BoundsSprites != 0
+	case TypeBox:
+		return flags&BoundsBoxes != 0
+	case TypePoint:
+		return flags&BoundsPoints != 0
+	}
+	return false
+}
+
+// GetAABB returns the axis-aligned bounding box, in world coordinates, of the objects of the current pose
+func (p *EntityPlayer) GetAABB(flags BoundsFlags) *Rect {
+	bounds := MakeEmptyRect()
+	for i := range p.currentKey.ObjectRefs {
+		object := p.GetKeyObjectToDraw(i)
+		if !p.boundsContain(object, flags) {
+			continue
+		}
+		corners, ok := p.getObjectCorners(object)
+		if !ok {
+			continue
+		}
+		for j := range corners {
+			bounds.AddPoint(corners[j].X(), corners[j].Y())
+		}
+	}
+	return bounds
+}
+
+// GetOBB returns the bounding box of the current pose, oriented like the player
+func (p *EntityPlayer) GetOBB(flags BoundsFlags) *OrientedRect {
+	if p.rootIsDirty {
+		p.updateRoot()
+	}
+	local := MakeEmptyRect()
+	for i := range p.currentKey.ObjectRefs {
+		object := p.GetKeyObjectToDraw(i)
+		if !p.boundsContain(object, flags) {
+			continue
+		}
+		corners, ok := p.getObjectCorners(object)
+		if !ok {
+			continue
+		}
+		for j := range corners {
+			corners[j].Sub(p.root.Position)
+			corners[j].Rotate(-p.root.Angle)
+			local.AddPoint(corners[j].X(), corners[j].Y())
+		}
+	}
+	if local.IsEmpty() {
+		return &OrientedRect{Center: p.root.Position.MakeCopy(), Angle: p.root.Angle}
+	}
+	center := MakePoint((local.MinX+local.MaxX)/2, (local.MinY+local.MaxY)/2)
+	center.Rotate(p.root.Angle)
+	center.Add(p.root.Position)
+	return &OrientedRect{
+		Center:     center,
+		HalfWidth:  local.Width() / 2,
+		HalfHeight: local.Height() / 2,
+		Angle:      p.root.Angle,
+	}
+}
+
+// GetAnimationBounds returns the bounds of an animation of the entity, relative to the player: position,
+// angle, scale and flipping are not applied. The bounds are the union of the poses at every key of the
+// animation and at regular steps of `Interval` ms in between (10ms if the animation has no interval).
+// They are sampled: a curve overshooting between two samples can go beyond them.
+// Overrides, constraints and IK are not considered. The result is computed once and cached.
+// nil is returned if the animation doesn't belong to the entity of the player.
+func (p *EntityPlayer) GetAnimationBounds(animation *Animation, flags BoundsFlags) *Rect {
+	if !p.entity.hasAnimation(animation) {
+		return nil
+	}
+	if p.animationBounds == nil {
+		p.animationBounds = make(map[*Animation]map[BoundsFlags]*Rect)
+	}
+	if p.animationBounds[animation] == nil {
+		p.animationBounds[animation] = make(map[BoundsFlags]*Rect)
+	}
+	if bounds, ok := p.animationBounds[animation][flags]; ok {
+		return bounds
+	}
+
+	sampler := MakeEntityPlayer(p.entity)
+	sampler.enabledCharacterMaps = append(sampler.enabledCharacterMaps, p.enabledCharacterMaps...)
+	sampler.copySpriteSwaps(p)
+	sampler.setAnimation(animation)
+
+	times := make(map[int]bool)
+	for i := range animation.Mainline.Keys {
+		times[animation.Mainline.Keys[i].Time] = true
+	}
+	for i := range animation.Timelines {
+		for j := range animation.Timelines[i].Keys {
+			times[animation.Timelines[i].Keys[j].Time] = true
+		}
+	}
+	step := animation.Interval
+	if step <= 0 {
+		step = 10
+	}
+	for t := 0; t <= animation.Length; t += step {
+		times[t] = true
+	}
+	times[animation.Length] = true
+
+	bounds := MakeEmptyRect()
+	for t := range times {
+		if t < 0 || t > animation.Length {
+			continue
+		}
+		sampler.time = t
+		sampler.Update(0)
+		bounds.Union(sampler.GetAABB(flags))
+	}
+	p.animationBounds[animation][flags] = bounds
+	return bounds
+}
+
+// PrecomputeBounds computes and caches the bounds of all the animations of the entity
+func (p *EntityPlayer) PrecomputeBounds(flags BoundsFlags) {
+	for i := range p.entity.Animations {
+		p.GetAnimationBounds(p.entity.Animations[i], flags)
+	}
+}
+
+// GetAnimationWorldBounds returns the bounds of the animation transformed like the player,
+// nil if the animation doesn't belong to the entity of the player
+func (p *EntityPlayer) GetAnimationWorldBounds(animation *Animation, flags BoundsFlags) *Rect {
+	if p.rootIsDirty {
+		p.updateRoot()
+	}
+	local := p.GetAnimationBounds(animation, flags)
+	if local == nil {
+		return nil
+	}
+	bounds := MakeEmptyRect()
+	if local.IsEmpty() {
+		return bounds
+	}
+	corners := local.Corners()
+	for i := range corners {
+		corners[i].Scale(p.root.Scale)
+		corners[i].Rotate(p.root.Angle)
+		corners[i].Add(p.root.Position)
+		bounds.AddPoint(corners[i].X(), corners[i].Y())
+	}
+	return bounds
+}
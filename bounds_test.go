@@ -0,0 +1,135 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+const boundsTestSCML = `<spriter_data scml_version="1.0">
+    <folder id="0">
+        <file id="0" name="body.png" width="10" height="20" pivot_x="0" pivot_y="0"/>
+        <file id="1" name="wide.png" width="30" height="10" pivot_x="0" pivot_y="0"/>
+    </folder>
+    <entity id="0" name="Thing">
+        <obj_info name="hitbox" type="box" w="4" h="6"/>
+        <character_map id="0" name="wide">
+            <map folder="0" file="0" target_folder="0" target_file="1"/>
+        </character_map>
+        <animation id="0" name="idle" length="1000">
+            <mainline>
+                <key id="0">
+                    <object_ref id="0" timeline="0" key="0" z_index="0"/>
+                    <object_ref id="1" timeline="1" key="0" z_index="1"/>
+                    <object_ref id="2" timeline="2" key="0" z_index="2"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="body">
+                <key id="0"><object folder="0" file="0" x="5" y="5"/></key>
+            </timeline>
+            <timeline id="1" name="hitbox" object_type="box">
+                <key id="0"><object x="-10" y="-10"/></key>
+            </timeline>
+            <timeline id="2" name="tip" object_type="point">
+                <key id="0"><object x="50" y="60"/></key>
+            </timeline>
+        </animation>
+        <animation id="1" name="move" length="1000" interval="100" looping="false">
+            <mainline>
+                <key id="0">
+                    <object_ref id="0" timeline="0" key="0" z_index="0"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="body">
+                <key id="0"><object folder="0" file="0" x="0" y="0"/></key>
+                <key id="1" time="1000"><object folder="0" file="0" x="100" y="0"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+func checkRect(t *testing.T, name string, r *Rect, minX float64, minY float64, maxX float64, maxY float64) {
+	t.Helper()
+	const tolerance = 1e-9
+	if r == nil || math.Abs(r.MinX-minX) > tolerance || math.Abs(r.MinY-minY) > tolerance ||
+		math.Abs(r.MaxX-maxX) > tolerance || math.Abs(r.MaxY-maxY) > tolerance {
+		t.Errorf("%s: expected (%g, %g)-(%g, %g), got %+v", name, minX, minY, maxX, maxY, r)
+	}
+}
+
+func TestAABB(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, boundsTestSCML).Entities[0])
+	p.Update(0)
+	checkRect(t, "sprites", p.GetAABB(BoundsSprites), 5, 5, 15, 25)
+	checkRect(t, "boxes", p.GetAABB(BoundsBoxes), -10, -10, -6, -4)
+	checkRect(t, "points", p.GetAABB(BoundsPoints), 50, 60, 50, 60)
+	checkRect(t, "all", p.GetAABB(BoundsAll), -10, -10, 50, 60)
+
+	p.EnableCharacterMap("wide")
+	p.Update(0)
+	checkRect(t, "character map", p.GetAABB(BoundsSprites), 5, 5, 35, 15)
+	p.HideObjectSprite("body")
+	p.Update(0)
+	if bounds := p.GetAABB(BoundsSprites); !bounds.IsEmpty() {
+		t.Errorf("expected no bounds for a hidden sprite, got %+v", bounds)
+	}
+
+	// Rotated by 90°: (x, y) becomes (-y, x)
+	p.ClearSpriteSwaps()
+	p.ClearCharacterMaps()
+	p.SetPosition(100, 0)
+	p.SetAngle(math.Pi / 2)
+	p.Update(0)
+	checkRect(t, "rotated", p.GetAABB(BoundsSprites), 75, 5, 95, 15)
+}
+
+func TestOBB(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, boundsTestSCML).Entities[0])
+	p.SetPosition(100, 0)
+	p.SetAngle(math.Pi / 2)
+	p.Update(0)
+	obb := p.GetOBB(BoundsSprites)
+	// The local bounds are (5, 5)-(15, 25), centered on (10, 15)
+	if math.Abs(obb.Center.X()-85) > 1e-9 || math.Abs(obb.Center.Y()-10) > 1e-9 ||
+		obb.HalfWidth != 5 || obb.HalfHeight != 10 || obb.Angle != math.Pi/2 {
+		t.Errorf("unexpected oriented bounds %+v centered on %v", obb, obb.Center)
+	}
+	// At 90° the oriented bounds cover the axis-aligned ones exactly
+	corners := MakeEmptyRect()
+	for _, corner := range obb.Corners() {
+		corners.AddPoint(corner.X(), corner.Y())
+	}
+	checkRect(t, "corners", corners, 75, 5, 95, 15)
+}
+
+func TestAnimationBounds(t *testing.T) {
+	model := loadTestModel(t, boundsTestSCML)
+	p := MakeEntityPlayer(model.Entities[0])
+	move := model.Entities[0].Animations[1]
+
+	checkRect(t, "move", p.GetAnimationBounds(move, BoundsSprites), 0, 0, 110, 20)
+	// The player is not transformed, the world bounds are
+	p.SetPosition(10, 0).SetScale(2)
+	checkRect(t, "move cached", p.GetAnimationBounds(move, BoundsSprites), 0, 0, 110, 20)
+	checkRect(t, "move world", p.GetAnimationWorldBounds(move, BoundsSprites), 10, 0, 230, 40)
+
+	// The character maps and the swaps invalidate the cache
+	p.EnableCharacterMap("wide")
+	checkRect(t, "character map", p.GetAnimationBounds(move, BoundsSprites), 0, 0, 130, 10)
+	p.HideObjectSprite("body")
+	if bounds := p.GetAnimationBounds(move, BoundsSprites); !bounds.IsEmpty() {
+		t.Errorf("expected no bounds for a hidden sprite, got %+v", bounds)
+	}
+	p.ClearSpriteSwaps()
+	p.DisableCharacterMap("wide")
+	checkRect(t, "restored", p.GetAnimationBounds(move, BoundsSprites), 0, 0, 110, 20)
+
+	// The animation of another entity has no bounds, and doesn't change the cached ones
+	other := loadTestModel(t, boundsTestSCML).Entities[0].Animations[1]
+	if bounds := p.GetAnimationBounds(other, BoundsSprites); bounds != nil {
+		t.Errorf("expected no bounds for the animation of another entity, got %+v", bounds)
+	}
+	if bounds := p.GetAnimationWorldBounds(other, BoundsSprites); bounds != nil {
+		t.Errorf("expected no world bounds for the animation of another entity, got %+v", bounds)
+	}
+	checkRect(t, "after another entity", p.GetAnimationBounds(move, BoundsSprites), 0, 0, 110, 20)
+}
@@ -0,0 +1,139 @@
+package spriter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Two maps replacing the arm, the second one without a name, and one hiding it
+const characterMapTestSCML = `<spriter_data scml_version="1.0">
+    <folder id="0">
+        <file id="0" name="body.png" width="32" height="64"/>
+        <file id="1" name="arm.png" width="40" height="10"/>
+        <file id="2" name="sword.png" width="50" height="8"/>
+        <file id="3" name="shield.png" width="30" height="30"/>
+    </folder>
+    <entity id="0" name="Hero">
+        <character_map id="0" name="sword">
+            <map folder="0" file="1" target_folder="0" target_file="2"/>
+        </character_map>
+        <character_map id="1">
+            <map folder="0" file="1" target_folder="0" target_file="3"/>
+            <map folder="0" file="0" target_folder="0" target_file="3"/>
+        </character_map>
+        <character_map id="2" name="unarmed">
+            <map folder="0" file="1"/>
+        </character_map>
+        <animation id="0" name="idle" length="1000">
+            <mainline>
+                <key id="0">
+                    <object_ref id="0" timeline="0" key="0" z_index="0"/>
+                    <object_ref id="1" timeline="1" key="0" z_index="1"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="body">
+                <key id="0"><object folder="0" file="0"/></key>
+            </timeline>
+            <timeline id="1" name="arm">
+                <key id="0"><object folder="0" file="1"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+func TestCharacterMapNames(t *testing.T) {
+	entity := loadTestModel(t, characterMapTestSCML).Entities[0]
+	var names []string
+	for _, characterMap := range entity.CharacterMaps {
+		names = append(names, characterMap.Name)
+	}
+	if expected := []string{"sword", "charMap1", "unarmed"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the maps %v, got %v", expected, names)
+	}
+}
+
+func TestCharacterMapStack(t *testing.T) {
+	body, arm := FolderAndFileToFileIndex(0, 0), FolderAndFileToFileIndex(0, 1)
+	sword, shield := FolderAndFileToFileIndex(0, 2), FolderAndFileToFileIndex(0, 3)
+
+	tests := []struct {
+		name    string
+		maps    []string
+		enabled []string
+		// File index drawn by each object
+		files map[string]int
+		// Map replacing the file of each object
+		replacedBy map[string]string
+	}{
+		{"none", nil, []string{},
+			map[string]int{"body": body, "arm": arm}, map[string]string{}},
+		{"one map", []string{"sword"}, []string{"sword"},
+			map[string]int{"body": body, "arm": sword}, map[string]string{"arm": "sword"}},
+		{"top map wins", []string{"sword", "charMap1"}, []string{"sword", "charMap1"},
+			map[string]int{"body": shield, "arm": shield}, map[string]string{"body": "charMap1", "arm": "charMap1"}},
+		{"top map wins only on its files", []string{"charMap1", "sword"}, []string{"charMap1", "sword"},
+			map[string]int{"body": shield, "arm": sword}, map[string]string{"body": "charMap1", "arm": "sword"}},
+		{"enabling again moves to the top", []string{"sword", "charMap1", "sword"}, []string{"charMap1", "sword"},
+			map[string]int{"body": shield, "arm": sword}, map[string]string{"body": "charMap1", "arm": "sword"}},
+		{"hiding map", []string{"sword", "unarmed"}, []string{"sword", "unarmed"},
+			map[string]int{"body": body, "arm": -1}, map[string]string{"arm": "unarmed"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := MakeEntityPlayer(loadTestModel(t, characterMapTestSCML).Entities[0])
+			for _, name := range test.maps {
+				if err := p.EnableCharacterMap(name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p.Update(0)
+			if enabled := p.GetEnabledCharacterMaps(); !reflect.DeepEqual(enabled, test.enabled) {
+				t.Errorf("expected the enabled maps %v, got %v", test.enabled, enabled)
+			}
+			for _, objectFile := range p.GetObjectFiles() {
+				if objectFile.FileIndex != test.files[objectFile.Name] {
+					t.Errorf("%s: expected file %d, got %d", objectFile.Name, test.files[objectFile.Name], objectFile.FileIndex)
+				}
+				if objectFile.CharacterMap != test.replacedBy[objectFile.Name] {
+					t.Errorf("%s: expected to be replaced by '%s', got '%s'",
+						objectFile.Name, test.replacedBy[objectFile.Name], objectFile.CharacterMap)
+				}
+			}
+
+			p.ClearCharacterMaps()
+			if enabled := p.GetEnabledCharacterMaps(); len(enabled) != 0 {
+				t.Errorf("expected no maps after clearing, got %v", enabled)
+			}
+			for _, objectFile := range p.GetObjectFiles() {
+				if objectFile.CharacterMap != "" || objectFile.File != objectFile.Original {
+					t.Errorf("%s: expected the original file after clearing the maps", objectFile.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestDisableCharacterMap(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, characterMapTestSCML).Entities[0])
+	p.EnableCharacterMap("sword")
+	p.EnableCharacterMap("charMap1")
+	p.DisableCharacterMap("charMap1")
+	p.Update(0)
+	if enabled := p.GetEnabledCharacterMaps(); !reflect.DeepEqual(enabled, []string{"sword"}) {
+		t.Errorf("expected only the sword map, got %v", enabled)
+	}
+	if index := p.GetMappedFileIndexForKeyObject(p.getObjectByName("arm")); index != FolderAndFileToFileIndex(0, 2) {
+		t.Errorf("expected the sword under the disabled map, got file %d", index)
+	}
+}
+
+func TestEnableUnknownCharacterMap(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, characterMapTestSCML).Entities[0])
+	p.EnableCharacterMap("sword")
+	if err := p.EnableCharacterMap("swrod"); err == nil {
+		t.Error("expected an error enabling a map which doesn't exist")
+	}
+	if enabled := p.GetEnabledCharacterMaps(); !reflect.DeepEqual(enabled, []string{"sword"}) {
+		t.Errorf("an unknown map must not change the enabled ones, got %v", enabled)
+	}
+}
@@ -0,0 +1,120 @@
+// Command spriter-convert converts a model between the supported formats: SCML, SCON and binary (.sprb).
+// Formats are chosen by the extensions of the files, unless -from and -to are given.
+// With -check, the converted file is read back and the poses of every animation are compared with
+// the original ones: the command fails if any value differs by more than the tolerance.
+// With -bench, both files are decoded many times and the average load times are printed.
+//
+// Usage:
+//
+//	spriter-convert [-from format] [-to format] [-check] [-tolerance 0.001] [-fps 60] [-bench n] input output
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	spriter "github.com/maxfish/go-spriter"
+)
+
+// Maximum number of differences printed by -check
+const maxDifferences = 20
+
+func main() {
+	from := flag.String("from", "", "format of the input, by default from its extension")
+	to := flag.String("to", "", "format of the output, by default from its extension")
+	check := flag.Bool("check", false, "verify that the output plays like the input")
+	tolerance := flag.Float64("tolerance", 0.001, "maximum difference allowed by -check (angles in degrees)")
+	frameRate := flag.Float64("fps", 60, "frame rate used by -check to sample the animations")
+	bench := flag.Int("bench", 0, "number of times the input and the output are decoded to measure the load time")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] input output\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	input, output := flag.Arg(0), flag.Arg(1)
+
+	inputFormat, err := getFormat(*from, input)
+	if err != nil {
+		fail(err, 2)
+	}
+	outputFormat, err := getFormat(*to, output)
+	if err != nil {
+		fail(err, 2)
+	}
+
+	data, err := ioutil.ReadFile(input)
+	if err != nil {
+		fail(err, 1)
+	}
+	model, err := spriter.DecodeModel(data, inputFormat)
+	if err != nil {
+		fail(fmt.Errorf("%s: %v", input, err), 1)
+	}
+
+	var converted bytes.Buffer
+	if err := spriter.EncodeModel(&converted, model, outputFormat); err != nil {
+		fail(err, 1)
+	}
+	if err := ioutil.WriteFile(output, converted.Bytes(), 0644); err != nil {
+		fail(err, 1)
+	}
+	fmt.Printf("%s (%s) -> %s (%s), %d bytes\n", input, inputFormat, output, outputFormat, converted.Len())
+	if *bench > 0 {
+		fmt.Printf("Load %s: %v\n", inputFormat, measureDecode(data, inputFormat, *bench))
+		fmt.Printf("Load %s: %v\n", outputFormat, measureDecode(converted.Bytes(), outputFormat, *bench))
+	}
+
+	if !*check {
+		return
+	}
+	result, err := spriter.DecodeModel(converted.Bytes(), outputFormat)
+	if err != nil {
+		fail(fmt.Errorf("%s: %v", output, err), 1)
+	}
+	differences := spriter.ComparePoses(model, result, *frameRate, *tolerance)
+	if len(differences) == 0 {
+		fmt.Println("Check passed: the poses are the same")
+		return
+	}
+	for i := range differences {
+		if i == maxDifferences {
+			fmt.Printf("... and %d more\n", len(differences)-maxDifferences)
+			break
+		}
+		fmt.Println(differences[i].String())
+	}
+	fmt.Fprintf(os.Stderr, "Check failed: %d differences\n", len(differences))
+	os.Exit(1)
+}
+
+// measureDecode returns the average time taken to decode the data
+func measureDecode(data []byte, format spriter.Format, times int) time.Duration {
+	start := time.Now()
+	for i := 0; i < times; i++ {
+		if _, err := spriter.DecodeModel(data, format); err != nil {
+			fail(err, 1)
+		}
+	}
+	return time.Since(start) / time.Duration(times)
+}
+
+func getFormat(name string, fileName string) (spriter.Format, error) {
+	if name != "" {
+		return spriter.ParseFormat(name)
+	}
+	return spriter.GetFormatFromFileName(fileName)
+}
+
+func fail(err error, status int) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(status)
+}
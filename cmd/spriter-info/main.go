@@ -0,0 +1,234 @@
+// Command spriter-info prints the content of a SCML file: entities, animations, timelines,
+// character maps, folders and files.
+//
+// Usage:
+//
+//	spriter-info [-json] file.scml
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	spriter "github.com/maxfish/go-spriter"
+)
+
+type fileInfo struct {
+	Id      int     `json:"id"`
+	Name    string  `json:"name"`
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+	PivotX  float64 `json:"pivotX"`
+	PivotY  float64 `json:"pivotY"`
+	Size    int64   `json:"size"`
+	Missing bool    `json:"missing,omitempty"`
+}
+
+type folderInfo struct {
+	Id    int        `json:"id"`
+	Name  string     `json:"name"`
+	Files []fileInfo `json:"files"`
+}
+
+type timelineInfo struct {
+	Id         int    `json:"id"`
+	Name       string `json:"name"`
+	ObjectType string `json:"objectType"`
+	Keys       int    `json:"keys"`
+}
+
+type animationInfo struct {
+	Id           int            `json:"id"`
+	Name         string         `json:"name"`
+	Length       int            `json:"length"`
+	Interval     int            `json:"interval"`
+	Looping      bool           `json:"looping"`
+	MainlineKeys int            `json:"mainlineKeys"`
+	TimelineKeys int            `json:"timelineKeys"`
+	Timelines    []timelineInfo `json:"timelines"`
+}
+
+type characterMapInfo struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Mappings int    `json:"mappings"`
+}
+
+type entityInfo struct {
+	Id            int                `json:"id"`
+	Name          string             `json:"name"`
+	Animations    []animationInfo    `json:"animations"`
+	CharacterMaps []characterMapInfo `json:"characterMaps"`
+}
+
+type modelInfo struct {
+	File             string       `json:"file"`
+	Generator        string       `json:"generator"`
+	GeneratorVersion string       `json:"generatorVersion"`
+	Entities         []entityInfo `json:"entities"`
+	Folders          []folderInfo `json:"folders"`
+	Warnings         []string     `json:"warnings"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run prints the information of the file given in the arguments and returns the exit status
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the information as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s [-json] file.scml\n", flags.Name())
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	fileName := flags.Arg(0)
+	model, err := spriter.LoadModel(fileName)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	info := collectInfo(fileName, model)
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return 1
+		}
+		return 0
+	}
+	printInfo(stdout, info)
+	return 0
+}
+
+func collectInfo(fileName string, model *spriter.Model) *modelInfo {
+	info := &modelInfo{
+		File:             fileName,
+		Generator:        model.Generator,
+		GeneratorVersion: model.GeneratorVersion,
+		Entities:         make([]entityInfo, 0),
+		Folders:          make([]folderInfo, 0),
+		Warnings:         make([]string, 0),
+	}
+
+	for _, folder := range model.Folders {
+		folderInfo := folderInfo{Id: folder.Id, Name: folder.Name, Files: make([]fileInfo, 0)}
+		for _, file := range folder.Files {
+			fileInfo := fileInfo{
+				Id:     file.Id,
+				Name:   file.Name,
+				Width:  file.Width,
+				Height: file.Height,
+				PivotX: file.PivotX,
+				PivotY: file.PivotY,
+			}
+			stat, err := os.Stat(filepath.Join(model.GetDir(), file.Name))
+			if err != nil {
+				fileInfo.Missing = true
+				info.Warnings = append(info.Warnings, fmt.Sprintf("missing image '%s' (folder %d, file %d)", file.Name, folder.Id, file.Id))
+			} else {
+				fileInfo.Size = stat.Size()
+			}
+			folderInfo.Files = append(folderInfo.Files, fileInfo)
+		}
+		info.Folders = append(info.Folders, folderInfo)
+	}
+
+	for _, entity := range model.Entities {
+		entityInfo := entityInfo{
+			Id:            entity.Id,
+			Name:          entity.Name,
+			Animations:    make([]animationInfo, 0),
+			CharacterMaps: make([]characterMapInfo, 0),
+		}
+		for _, animation := range entity.Animations {
+			animationInfo := animationInfo{
+				Id:           animation.Id,
+				Name:         animation.Name,
+				Length:       animation.Length,
+				Interval:     animation.Interval,
+				Looping:      animation.Looping,
+				MainlineKeys: len(animation.Mainline.Keys),
+				Timelines:    make([]timelineInfo, 0),
+			}
+			for _, timeline := range animation.Timelines {
+				animationInfo.TimelineKeys += len(timeline.Keys)
+				animationInfo.Timelines = append(animationInfo.Timelines, timelineInfo{
+					Id:         timeline.Id,
+					Name:       timeline.Name,
+					ObjectType: string(timeline.ObjectType),
+					Keys:       len(timeline.Keys),
+				})
+			}
+			entityInfo.Animations = append(entityInfo.Animations, animationInfo)
+		}
+		for _, characterMap := range entity.CharacterMaps {
+			entityInfo.CharacterMaps = append(entityInfo.CharacterMaps, characterMapInfo{
+				Id:       characterMap.Id,
+				Name:     characterMap.Name,
+				Mappings: len(characterMap.Maps),
+			})
+		}
+		info.Entities = append(info.Entities, entityInfo)
+	}
+	return info
+}
+
+func printInfo(w io.Writer, info *modelInfo) {
+	fmt.Fprintf(w, "%s (%s %s)\n", info.File, info.Generator, info.GeneratorVersion)
+
+	fmt.Fprintf(w, "\nEntities: %d\n", len(info.Entities))
+	for _, entity := range info.Entities {
+		fmt.Fprintf(w, "  [%d] %s\n", entity.Id, entity.Name)
+		fmt.Fprintf(w, "    Animations: %d\n", len(entity.Animations))
+		for _, animation := range entity.Animations {
+			looping := "looping"
+			if !animation.Looping {
+				looping = "not looping"
+			}
+			fmt.Fprintf(w, "      [%d] %s: %dms, %s, %d mainline keys, %d timeline keys\n",
+				animation.Id, animation.Name, animation.Length, looping, animation.MainlineKeys, animation.TimelineKeys)
+			for _, timeline := range animation.Timelines {
+				fmt.Fprintf(w, "        timeline [%d] %s (%s): %d keys\n", timeline.Id, timeline.Name, timeline.ObjectType, timeline.Keys)
+			}
+		}
+		fmt.Fprintf(w, "    Character maps: %d\n", len(entity.CharacterMaps))
+		for _, characterMap := range entity.CharacterMaps {
+			fmt.Fprintf(w, "      [%d] %s: %d mappings\n", characterMap.Id, characterMap.Name, characterMap.Mappings)
+		}
+	}
+
+	fmt.Fprintf(w, "\nFolders: %d\n", len(info.Folders))
+	for _, folder := range info.Folders {
+		fmt.Fprintf(w, "  [%d] %s\n", folder.Id, folder.Name)
+		for _, file := range folder.Files {
+			size := fmt.Sprintf("%d bytes", file.Size)
+			if file.Missing {
+				size = "MISSING"
+			}
+			fmt.Fprintf(w, "    [%d] %s: %dx%d, pivot %g,%g, %s\n", file.Id, file.Name, file.Width, file.Height, file.PivotX, file.PivotY, size)
+		}
+	}
+
+	if len(info.Warnings) > 0 {
+		fmt.Fprintf(w, "\nWarnings: %d\n", len(info.Warnings))
+		for _, warning := range info.Warnings {
+			fmt.Fprintf(w, "  %s\n", warning)
+		}
+	}
+}
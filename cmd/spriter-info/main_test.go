@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	spriter "github.com/maxfish/go-spriter"
+)
+
+const testSCML = `<spriter_data scml_version="1.0" generator="BrashMonkey Spriter" generator_version="r11">
+    <folder id="0" name="parts">
+        <file id="0" name="parts/body.png" width="32" height="64" pivot_x="0.5" pivot_y="0"/>
+        <file id="1" name="parts/sword.png" width="50" height="8" pivot_x="0" pivot_y="0.5"/>
+    </folder>
+    <entity id="0" name="Hero">
+        <character_map id="0" name="unarmed">
+            <map folder="0" file="1"/>
+        </character_map>
+        <animation id="0" name="walk" length="1000" interval="100">
+            <mainline>
+                <key id="0">
+                    <bone_ref id="0" timeline="0" key="0"/>
+                    <object_ref id="0" parent="0" timeline="1" key="0" z_index="0"/>
+                    <object_ref id="1" parent="0" timeline="2" key="0" z_index="1"/>
+                </key>
+                <key id="1" time="500">
+                    <bone_ref id="0" timeline="0" key="1"/>
+                    <object_ref id="0" parent="0" timeline="1" key="0" z_index="0"/>
+                    <object_ref id="1" parent="0" timeline="2" key="0" z_index="1"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="root" object_type="bone">
+                <key id="0"><bone x="0" y="0" angle="0"/></key>
+                <key id="1" time="500"><bone x="10" y="0" angle="0"/></key>
+            </timeline>
+            <timeline id="1" name="body">
+                <key id="0"><object folder="0" file="0"/></key>
+            </timeline>
+            <timeline id="2" name="sword">
+                <key id="0"><object folder="0" file="1"/></key>
+            </timeline>
+        </animation>
+        <animation id="1" name="idle" length="600" looping="false">
+            <mainline>
+                <key id="0"><object_ref id="0" timeline="0" key="0" z_index="0"/></key>
+            </mainline>
+            <timeline id="0" name="body">
+                <key id="0"><object folder="0" file="0"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+// writeTestModel writes the model and the image of the body, the one of the sword is missing
+func writeTestModel(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "parts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "parts", "body.png"), make([]byte, 42), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(dir, "hero.scml")
+	if err := ioutil.WriteFile(fileName, []byte(testSCML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestCollectInfo(t *testing.T) {
+	fileName := writeTestModel(t)
+	model, err := spriter.LoadModel(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := collectInfo(fileName, model)
+
+	if info.File != fileName || info.Generator != "BrashMonkey Spriter" || info.GeneratorVersion != "r11" {
+		t.Errorf("unexpected header %s, %s %s", info.File, info.Generator, info.GeneratorVersion)
+	}
+	if len(info.Entities) != 1 || info.Entities[0].Name != "Hero" {
+		t.Fatalf("expected the entity Hero, got %+v", info.Entities)
+	}
+	entity := info.Entities[0]
+	if len(entity.CharacterMaps) != 1 || entity.CharacterMaps[0].Name != "unarmed" || entity.CharacterMaps[0].Mappings != 1 {
+		t.Errorf("unexpected character maps %+v", entity.CharacterMaps)
+	}
+	if len(entity.Animations) != 2 {
+		t.Fatalf("expected 2 animations, got %+v", entity.Animations)
+	}
+	walk, idle := entity.Animations[0], entity.Animations[1]
+	if walk.Name != "walk" || walk.Length != 1000 || walk.Interval != 100 || !walk.Looping ||
+		walk.MainlineKeys != 2 || walk.TimelineKeys != 4 || len(walk.Timelines) != 3 {
+		t.Errorf("unexpected animation %+v", walk)
+	}
+	if timeline := walk.Timelines[0]; timeline.Name != "root" || timeline.ObjectType != "bone" || timeline.Keys != 2 {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+	if timeline := walk.Timelines[2]; timeline.Name != "sword" || timeline.ObjectType != "sprite" || timeline.Keys != 1 {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+	if idle.Name != "idle" || idle.Length != 600 || idle.Looping || idle.MainlineKeys != 1 || idle.TimelineKeys != 1 {
+		t.Errorf("unexpected animation %+v", idle)
+	}
+
+	if len(info.Folders) != 1 || info.Folders[0].Name != "parts" || len(info.Folders[0].Files) != 2 {
+		t.Fatalf("expected the folder parts with 2 files, got %+v", info.Folders)
+	}
+	body, sword := info.Folders[0].Files[0], info.Folders[0].Files[1]
+	if body.Name != "parts/body.png" || body.Width != 32 || body.Height != 64 || body.PivotX != 0.5 || body.PivotY != 0 ||
+		body.Size != 42 || body.Missing {
+		t.Errorf("unexpected file %+v", body)
+	}
+	if sword.Name != "parts/sword.png" || !sword.Missing || sword.Size != 0 {
+		t.Errorf("unexpected file %+v", sword)
+	}
+	if len(info.Warnings) != 1 || !strings.Contains(info.Warnings[0], "parts/sword.png") {
+		t.Errorf("expected a warning for the missing sword, got %v", info.Warnings)
+	}
+}
+
+func TestRun(t *testing.T) {
+	fileName := writeTestModel(t)
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-json", fileName}, &stdout, &stderr); status != 0 {
+		t.Fatalf("expected status 0, got %d: %s", status, stderr.String())
+	}
+	var info modelInfo
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Entities) != 1 || len(info.Entities[0].Animations) != 2 || info.Entities[0].Animations[1].Name != "idle" ||
+		len(info.Folders) != 1 || len(info.Folders[0].Files) != 2 || !info.Folders[0].Files[1].Missing {
+		t.Errorf("unexpected JSON output %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if status := run([]string{fileName}, &stdout, &stderr); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+	for _, expected := range []string{"[0] Hero", "[1] idle: 600ms, not looping", "timeline [0] root (bone): 2 keys", "parts/sword.png: 50x8, pivot 0,0.5, MISSING"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected '%s' in\n%s", expected, stdout.String())
+		}
+	}
+
+	for _, args := range [][]string{{}, {fileName, fileName}, {"-unknown", fileName}} {
+		if status := run(args, &stdout, &stderr); status != 2 {
+			t.Errorf("%v: expected status 2, got %d", args, status)
+		}
+	}
+	if status := run([]string{filepath.Join(filepath.Dir(fileName), "missing.scml")}, &stdout, &stderr); status != 1 {
+		t.Errorf("expected status 1 for a missing file, got %d", status)
+	}
+}
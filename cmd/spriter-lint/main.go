@@ -0,0 +1,160 @@
+// Command spriter-lint checks SCML files for problems: keys using missing files, unused files,
+// unreferenced timelines, zero-length animations, broken character maps, duplicate names, pivots out of range,
+// unknown curves.
+// It exits with status 1 when any error is found (or any warning, with -strict).
+//
+// Usage:
+//
+//	spriter-lint [-json] [-strict] [-disable rule,...] [-severity rule=level,...] file.scml...
+//	spriter-lint -rules
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	spriter "github.com/maxfish/go-spriter"
+)
+
+type issueInfo struct {
+	File     string `json:"file"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run lints the files given in the arguments and returns the exit status
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the issues as JSON")
+	strict := flags.Bool("strict", false, "fail on warnings too")
+	disable := flags.String("disable", "", "comma separated list of rules to skip")
+	severities := flags.String("severity", "", "comma separated list of rule=level overrides, level being info, warning or error")
+	listRules := flags.Bool("rules", false, "list the available rules and exit")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s [options] file.scml...\n", flags.Name())
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *listRules {
+		for _, rule := range spriter.LintRules {
+			fmt.Fprintf(stdout, "%-22s %-8s %s\n", rule.Id, rule.Severity, rule.Description)
+		}
+		return 0
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	options, err := parseOptions(*disable, *severities)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 2
+	}
+
+	failThreshold := spriter.LintError
+	if *strict {
+		failThreshold = spriter.LintWarning
+	}
+	failed := false
+	issues := make([]issueInfo, 0)
+	for _, fileName := range flags.Args() {
+		model, err := spriter.LoadModel(fileName)
+		if err != nil {
+			issues = append(issues, issueInfo{File: fileName, Rule: "load", Severity: spriter.LintError.String(), Message: err.Error()})
+			failed = true
+			continue
+		}
+		for _, issue := range spriter.Lint(model, options) {
+			issues = append(issues, issueInfo{
+				File:     fileName,
+				Rule:     issue.Rule,
+				Severity: issue.Severity.String(),
+				Location: issue.Location,
+				Message:  issue.Message,
+			})
+			if issue.Severity >= failThreshold {
+				failed = true
+			}
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(issues); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return 1
+		}
+	} else {
+		printIssues(stdout, issues)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func parseOptions(disable string, severities string) (*spriter.LintOptions, error) {
+	options := spriter.MakeLintOptions()
+	for _, id := range splitList(disable) {
+		if spriter.GetLintRule(id) == nil {
+			return nil, fmt.Errorf("unknown rule '%s'", id)
+		}
+		options.Disabled[id] = true
+	}
+	for _, entry := range splitList(severities) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid severity override '%s', expected rule=level", entry)
+		}
+		if spriter.GetLintRule(parts[0]) == nil {
+			return nil, fmt.Errorf("unknown rule '%s'", parts[0])
+		}
+		severity, err := spriter.ParseLintSeverity(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		options.Severities[parts[0]] = severity
+	}
+	return options, nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func printIssues(w io.Writer, issues []issueInfo) {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Severity]++
+		if issue.Location == "" {
+			fmt.Fprintf(w, "%s: %s [%s] %s\n", issue.File, issue.Severity, issue.Rule, issue.Message)
+		} else {
+			fmt.Fprintf(w, "%s: %s [%s] %s: %s\n", issue.File, issue.Severity, issue.Rule, issue.Location, issue.Message)
+		}
+	}
+	fmt.Fprintf(w, "%d errors, %d warnings, %d infos\n", counts["error"], counts["warning"], counts["info"])
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSCML = `<spriter_data scml_version="1.0">
+    <folder id="0">
+        <file id="0" name="body.png" width="10" height="10" pivot_x="0.5" pivot_y="0.5"/>
+        %s
+    </folder>
+    <entity id="0" name="Hero">
+        <animation id="0" name="idle" length="%s">
+            <mainline>
+                <key id="0"><object_ref id="0" timeline="0" key="0" z_index="0"/></key>
+            </mainline>
+            <timeline id="0" name="body">
+                <key id="0"><object folder="0" file="0"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+// writeTestFiles writes a clean model, a model with an unused file (a warning) and one with a zero-length
+// animation (an error), with their images
+func writeTestFiles(t *testing.T) (clean string, warning string, broken string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"body.png", "unused.png"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name string, extraFile string, length string) string {
+		scml := strings.Replace(strings.Replace(testSCML, "%s", extraFile, 1), "%s", length, 1)
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(scml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	unused := `<file id="1" name="unused.png" width="10" height="10" pivot_x="0" pivot_y="0"/>`
+	return write("clean.scml", "", "1000"), write("warning.scml", unused, "1000"), write("broken.scml", "", "0")
+}
+
+func TestRunExitStatus(t *testing.T) {
+	clean, warning, broken := writeTestFiles(t)
+	for _, test := range []struct {
+		args   []string
+		status int
+	}{
+		{[]string{clean}, 0},
+		{[]string{warning}, 0},
+		{[]string{"-strict", warning}, 1},
+		{[]string{"-strict", "-disable", "unused-file", warning}, 0},
+		{[]string{broken}, 1},
+		{[]string{clean, broken}, 1},
+		{[]string{"-severity", "zero-length-animation=warning", broken}, 0},
+		{[]string{"-severity", "unused-file=error", warning}, 1},
+		{[]string{filepath.Join(filepath.Dir(clean), "missing.scml")}, 1},
+		{[]string{}, 2},
+		{[]string{"-unknown", clean}, 2},
+		{[]string{"-disable", "no-such-rule", clean}, 2},
+		{[]string{"-severity", "unused-file=fatal", clean}, 2},
+		{[]string{"-severity", "unused-file", clean}, 2},
+		{[]string{"-rules"}, 0},
+	} {
+		var stdout, stderr bytes.Buffer
+		if status := run(test.args, &stdout, &stderr); status != test.status {
+			t.Errorf("%v: expected status %d, got %d\n%s%s", test.args, test.status, status, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	_, warning, broken := writeTestFiles(t)
+	var stdout bytes.Buffer
+	if status := run([]string{"-json", warning, broken}, &stdout, os.Stderr); status != 1 {
+		t.Errorf("expected status 1, got %d", status)
+	}
+	var issues []issueInfo
+	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", issues)
+	}
+	if issues[0].File != warning || issues[0].Rule != "unused-file" || issues[0].Severity != "warning" {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if issues[1].File != broken || issues[1].Rule != "zero-length-animation" || issues[1].Severity != "error" {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+}
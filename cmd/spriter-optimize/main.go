@@ -0,0 +1,100 @@
+// Command spriter-optimize removes the redundant keys of the animations of a model, the ones which can be
+// reproduced by interpolating the keys around them, and writes the optimized model.
+// The format of the output comes from its extension (SCML, SCON or binary).
+//
+// Usage:
+//
+//	spriter-optimize [-position 0.1] [-angle 0.1] [-scale 0.001] [-pivot 0.001] [-alpha 0.001] [-check] input output
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	spriter "github.com/maxfish/go-spriter"
+)
+
+func main() {
+	options := spriter.MakeKeyReductionOptions()
+	flag.Float64Var(&options.Position, "position", options.Position, "tolerance on the positions")
+	flag.Float64Var(&options.Angle, "angle", options.Angle, "tolerance on the angles, in degrees")
+	flag.Float64Var(&options.Scale, "scale", options.Scale, "tolerance on the scales")
+	flag.Float64Var(&options.Pivot, "pivot", options.Pivot, "tolerance on the pivots, relative to the size of the images")
+	flag.Float64Var(&options.Alpha, "alpha", options.Alpha, "tolerance on the alpha")
+	check := flag.Bool("check", false, "compare the poses of the optimized model with the original ones and print the largest differences")
+	frameRate := flag.Float64("fps", 60, "frame rate used by -check to sample the animations")
+	verbose := flag.Bool("v", false, "print the result of each animation")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] input output\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	input, output := flag.Arg(0), flag.Arg(1)
+
+	model, err := spriter.LoadModel(input)
+	if err != nil {
+		fail(err)
+	}
+	report := spriter.ReduceKeys(model, options)
+	if err := spriter.SaveModel(model, output); err != nil {
+		fail(err)
+	}
+
+	if *verbose {
+		for _, animation := range report.Animations {
+			if animation.Skipped != "" {
+				fmt.Printf("  %s/%s: skipped, %s\n", animation.Entity, animation.Animation, animation.Skipped)
+				continue
+			}
+			fmt.Printf("  %s/%s: %d -> %d keys\n", animation.Entity, animation.Animation, animation.KeysBefore, animation.KeysAfter)
+		}
+	}
+	fmt.Printf("%s -> %s: %s\n", input, output, report.String())
+	if inputInfo, err := os.Stat(input); err == nil {
+		if outputInfo, err := os.Stat(output); err == nil {
+			fmt.Printf("File size: %d -> %d bytes\n", inputInfo.Size(), outputInfo.Size())
+		}
+	}
+
+	if !*check {
+		return
+	}
+	original, err := spriter.LoadModel(input)
+	if err != nil {
+		fail(err)
+	}
+	optimized, err := spriter.LoadModel(output)
+	if err != nil {
+		fail(err)
+	}
+	// Everything is reported, to find the largest difference of each property
+	largest := make(map[string]float64)
+	properties := make([]string, 0)
+	for _, difference := range spriter.ComparePoses(original, optimized, *frameRate, 0) {
+		if _, ok := largest[difference.Property]; !ok {
+			properties = append(properties, difference.Property)
+		}
+		if difference.Delta >= largest[difference.Property] {
+			largest[difference.Property] = difference.Delta
+		}
+	}
+	if len(properties) == 0 {
+		fmt.Println("Check: the poses are the same")
+		return
+	}
+	fmt.Println("Check: largest differences in world space")
+	for _, property := range properties {
+		fmt.Printf("  %s: %g\n", property, largest[property])
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
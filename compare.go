@@ -0,0 +1,143 @@
+package spriter
+
+import (
+	"fmt"
+	"math"
+)
+
+// PoseDifference is a difference found between two models playing the same animation
+type PoseDifference struct {
+	Entity    string
+	Animation string
+	Time      int
+	// Name of the timeline, empty for the differences of structure
+	Timeline string
+	// What is different, e.g. "x", "angle", "file"
+	Property string
+	Delta    float64
+}
+
+func (d *PoseDifference) String() string {
+	if d.Timeline == "" {
+		return fmt.Sprintf("entity '%s' > animation '%s': %s differs", d.Entity, d.Animation, d.Property)
+	}
+	return fmt.Sprintf("entity '%s' > animation '%s' at %dms: %s of '%s' differs by %g",
+		d.Entity, d.Animation, d.Time, d.Property, d.Timeline, d.Delta)
+}
+
+// ComparePoses plays every animation of both models and compares the world transformations of the bones and
+// objects, sampled at the given frame rate. Entities and animations are matched by name, timelines by position.
+// Values differing by more than the tolerance are reported; angles are compared in degrees.
+func ComparePoses(a *Model, b *Model, frameRate float64, tolerance float64) []PoseDifference {
+	differences := make([]PoseDifference, 0)
+	for _, entityA := range a.Entities {
+		entityB := b.GetEntityByName(entityA.Name)
+		if entityB == nil {
+			differences = append(differences, PoseDifference{Entity: entityA.Name, Property: "presence of the entity"})
+			continue
+		}
+		playerA := MakeEntityPlayer(entityA)
+		playerB := MakeEntityPlayer(entityB)
+		for _, animationA := range entityA.Animations {
+			animationB := entityB.getAnimationByName(animationA.Name)
+			structure := func(property string) {
+				differences = append(differences, PoseDifference{Entity: entityA.Name, Animation: animationA.Name, Property: property})
+			}
+			switch {
+			case animationB == nil:
+				structure("presence of the animation")
+				continue
+			case animationA.Length != animationB.Length:
+				structure("length")
+			case animationA.Looping != animationB.Looping:
+				structure("looping")
+			case len(animationA.Timelines) != len(animationB.Timelines):
+				structure("number of timelines")
+				continue
+			}
+			if len(animationA.Mainline.Keys) == 0 || len(animationB.Mainline.Keys) == 0 {
+				if len(animationA.Mainline.Keys) != len(animationB.Mainline.Keys) {
+					structure("number of mainline keys")
+				}
+				continue
+			}
+
+			playerA.setAnimation(animationA)
+			playerB.setAnimation(animationB)
+			for _, time := range getFrameTimes(animationA, frameRate) {
+				playerA.time, playerB.time = time, time
+				playerA.Update(0)
+				playerB.Update(0)
+				differences = append(differences, comparePlayers(playerA, playerB, time, tolerance)...)
+			}
+		}
+	}
+	for _, entityB := range b.Entities {
+		if a.GetEntityByName(entityB.Name) == nil {
+			differences = append(differences, PoseDifference{Entity: entityB.Name, Property: "presence of the entity"})
+		}
+	}
+	return differences
+}
+
+func comparePlayers(a *EntityPlayer, b *EntityPlayer, time int, tolerance float64) []PoseDifference {
+	differences := make([]PoseDifference, 0)
+	for i, timeline := range a.animation.Timelines {
+		report := func(property string, delta float64) {
+			differences = append(differences, PoseDifference{
+				Entity:    a.entity.Name,
+				Animation: a.animation.Name,
+				Time:      time,
+				Timeline:  timeline.Name,
+				Property:  property,
+				Delta:     delta,
+			})
+		}
+		compare := func(property string, valueA float64, valueB float64) {
+			if delta := math.Abs(valueA - valueB); delta > tolerance {
+				report(property, delta)
+			}
+		}
+
+		keyA, keyB := a.unmappedInterpolatedKeys[i], b.unmappedInterpolatedKeys[i]
+		if keyA.active != keyB.active {
+			report("visibility", 1)
+			continue
+		}
+		if !keyA.active {
+			continue
+		}
+		objectA, objectB := keyA.object, keyB.object
+		compare("x", objectA.Position.X(), objectB.Position.X())
+		compare("y", objectA.Position.Y(), objectB.Position.Y())
+		angle := math.Abs(math.Remainder(objectA.Angle-objectB.Angle, 2*math.Pi)) * 180 / math.Pi
+		compare("angle", angle, 0)
+		compare("scale_x", objectA.Scale.X(), objectB.Scale.X())
+		compare("scale_y", objectA.Scale.Y(), objectB.Scale.Y())
+		compare("alpha", objectA.Alpha, objectB.Alpha)
+		if objectA.objectType == TypeSprite {
+			compare("pivot_x", objectA.Pivot.X(), objectB.Pivot.X())
+			compare("pivot_y", objectA.Pivot.Y(), objectB.Pivot.Y())
+			if objectA.fileIndex != objectB.fileIndex {
+				report("file", 1)
+			}
+		}
+	}
+
+	orderA, orderB := a.GetDrawOrder(), b.GetDrawOrder()
+	same := len(orderA) == len(orderB)
+	for i := 0; same && i < len(orderA); i++ {
+		same = a.currentKey.ObjectRefs[orderA[i]].Timeline == b.currentKey.ObjectRefs[orderB[i]].Timeline
+	}
+	if !same {
+		differences = append(differences, PoseDifference{
+			Entity:    a.entity.Name,
+			Animation: a.animation.Name,
+			Time:      time,
+			Timeline:  "*",
+			Property:  "draw order",
+			Delta:     1,
+		})
+	}
+	return differences
+}
@@ -0,0 +1,134 @@
+package spriter
+
+import "math"
+
+// LookAtConstraint rotates a bone so that one of its local axes points at a target in world coordinates.
+// The rotation is an offset on top of the animated angle, it can be limited to [MinAngle, MaxAngle]
+// and smoothed over time. Angles are in radians.
+type LookAtConstraint struct {
+	Name     string
+	Bone     string
+	Axis     *Point
+	Target   *Point
+	Weight   float64
+	MinAngle float64
+	MaxAngle float64
+	// Time (in ms) the bone takes to cover ~63% of the remaining rotation. 0 means no smoothing
+	Smoothing float64
+
+	offset float64
+	active bool
+}
+
+func MakeLookAtConstraint(name string, bone string) *LookAtConstraint {
+	return &LookAtConstraint{
+		Name:     name,
+		Bone:     bone,
+		Axis:     MakePoint(1, 0),
+		Target:   MakePoint(0, 0),
+		Weight:   1,
+		MinAngle: -math.Pi,
+		MaxAngle: math.Pi,
+	}
+}
+
+func (c *LookAtConstraint) SetAxis(x float64, y float64) *LookAtConstraint {
+	c.Axis.SetCoords(x, y)
+	return c
+}
+
+func (c *LookAtConstraint) SetTarget(x float64, y float64) *LookAtConstraint {
+	c.Target.SetCoords(x, y)
+	return c
+}
+
+func (c *LookAtConstraint) SetWeight(weight float64) *LookAtConstraint {
+	c.Weight = weight
+	return c
+}
+
+func (c *LookAtConstraint) SetLimits(minAngle float64, maxAngle float64) *LookAtConstraint {
+	c.MinAngle = minAngle
+	c.MaxAngle = maxAngle
+	return c
+}
+
+func (c *LookAtConstraint) SetSmoothing(millisecs float64) *LookAtConstraint {
+	c.Smoothing = millisecs
+	return c
+}
+
+// Reset drops the smoothing state, the bone restarts from its animated angle
+func (c *LookAtConstraint) Reset() {
+	c.offset = 0
+	c.active = false
+}
+
+// apply rotates the bone, whose world coordinates have already been computed
+func (c *LookAtConstraint) apply(local *TimelineKeyObject, world *TimelineKeyObject, parent *TimelineKeyObject, timeDeltaMs int) {
+	axis := c.Axis.MakeCopy()
+	axis.Scale(world.Scale)
+	axis.Rotate(world.Angle)
+	current := math.Atan2(axis.Y(), axis.X())
+	desired := math.Atan2(c.Target.Y()-world.Position.Y(), c.Target.X()-world.Position.X())
+	parentSign := signum(parent.Scale.X()) * signum(parent.Scale.Y())
+	offset := math.Remainder(desired-current, 2*math.Pi) * parentSign
+	offset = math.Max(c.MinAngle, math.Min(c.MaxAngle, offset))
+
+	if c.Smoothing > 0 && c.active {
+		offset = c.offset + (offset-c.offset)*(1-math.Exp(-float64(timeDeltaMs)/c.Smoothing))
+	} else if c.Smoothing > 0 {
+		offset = 0
+	}
+	c.offset = offset
+	c.active = true
+
+	local.Angle += offset * math.Max(0, math.Min(1, c.Weight))
+	world.setWithBone(local)
+	world.unmapCoordinates(parent)
+}
+
+// AddConstraint adds a constraint to the player, replacing the one with the same name.
+// Constraints on the same bone are applied in the order they have been added.
+func (p *EntityPlayer) AddConstraint(constraint *LookAtConstraint) {
+	for i := range p.constraints {
+		if p.constraints[i].Name == constraint.Name {
+			p.constraints[i] = constraint
+			return
+		}
+	}
+	p.constraints = append(p.constraints, constraint)
+}
+
+func (p *EntityPlayer) GetConstraint(name string) *LookAtConstraint {
+	for i := range p.constraints {
+		if p.constraints[i].Name == name {
+			return p.constraints[i]
+		}
+	}
+	return nil
+}
+
+func (p *EntityPlayer) RemoveConstraint(name string) {
+	for i := range p.constraints {
+		if p.constraints[i].Name == name {
+			p.constraints = append(p.constraints[:i], p.constraints[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *EntityPlayer) applyConstraints(ref *ObjectRef, boneName string) {
+	for i := range p.constraints {
+		c := p.constraints[i]
+		if c.Bone != boneName {
+			continue
+		}
+		c.apply(
+			p.interpolatedKeys[ref.Timeline].object,
+			p.unmappedInterpolatedKeys[ref.Timeline].object,
+			p.getParentObject(ref),
+			p.timeDelta,
+		)
+	}
+}
@@ -0,0 +1,89 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+// A single bone at the origin rotated by 170°
+const constraintTestSCML = `<spriter_data scml_version="1.0">
+    <entity id="0" name="Head">
+        <obj_info name="head" type="bone" w="20" h="10"/>
+        <animation id="0" name="idle" length="1000">
+            <mainline>
+                <key id="0">
+                    <bone_ref id="0" timeline="0" key="0"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="head" object_type="bone">
+                <key id="0"><bone x="0" y="0" angle="170"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func TestLookAtConstraint(t *testing.T) {
+	direction := func(angle float64) (float64, float64) {
+		return 10 * math.Cos(radians(angle)), 10 * math.Sin(radians(angle))
+	}
+	tests := []struct {
+		name       string
+		constraint func(c *LookAtConstraint)
+		// Direction of the target from the bone
+		target float64
+		angle  float64
+	}{
+		{"reaches the target", func(c *LookAtConstraint) {}, 90, 90},
+		{"local axis", func(c *LookAtConstraint) { c.SetAxis(0, 1) }, 90, 0},
+		{"half weight", func(c *LookAtConstraint) { c.SetWeight(0.5) }, 90, 130},
+		{"zero weight", func(c *LookAtConstraint) { c.SetWeight(0) }, 90, 170},
+		{"min angle", func(c *LookAtConstraint) { c.SetLimits(radians(-30), radians(30)) }, 90, 140},
+		{"max angle", func(c *LookAtConstraint) { c.SetLimits(radians(-30), radians(30)) }, 260, 200},
+		{"within the limits", func(c *LookAtConstraint) { c.SetLimits(radians(-30), radians(30)) }, 150, 150},
+		// From 170° to -170° the shortest way is across ±180°, a positive rotation
+		{"across ±180°", func(c *LookAtConstraint) { c.SetLimits(0, radians(30)) }, -170, -170},
+		{"across ±180° clamped", func(c *LookAtConstraint) { c.SetLimits(0, radians(30)) }, -150, -160},
+		{"across ±180° forbidden", func(c *LookAtConstraint) { c.SetLimits(radians(-30), 0) }, -170, 170},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := MakeEntityPlayer(loadTestModel(t, constraintTestSCML).Entities[0])
+			c := MakeLookAtConstraint("look", "head").SetTarget(direction(test.target))
+			test.constraint(c)
+			p.AddConstraint(c)
+			p.Update(0)
+			checkBone(t, p, "head", 0, 0, test.angle)
+		})
+	}
+}
+
+func TestLookAtConstraintSmoothing(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, constraintTestSCML).Entities[0])
+	c := MakeLookAtConstraint("look", "head").SetTarget(0, 10).SetSmoothing(100)
+	p.AddConstraint(c)
+
+	// The first update starts from the animated angle
+	p.Update(100)
+	checkBone(t, p, "head", 0, 0, 170)
+	// Each update covers 1 - e^-1 of the remaining rotation, from 170° to 90°
+	remaining := 80.0
+	for i := 0; i < 10; i++ {
+		p.Update(100)
+		remaining *= math.Exp(-1)
+		checkBone(t, p, "head", 0, 0, 90+remaining)
+	}
+
+	c.Reset()
+	p.Update(100)
+	checkBone(t, p, "head", 0, 0, 170)
+	p.Update(100)
+	checkBone(t, p, "head", 0, 0, 90+80*math.Exp(-1))
+
+	p.RemoveConstraint("look")
+	p.Update(100)
+	checkBone(t, p, "head", 0, 0, 170)
+}
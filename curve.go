@@ -1,9 +1,6 @@
 package spriter
 
-import (
-	"fmt"
-	"math"
-)
+import "fmt"
 
 type CurveType int
 
@@ -15,12 +12,16 @@ const (
 	TypeQuartic   CurveType = 4
 	TypeQuintic   CurveType = 5
 	TypeBezier    CurveType = 6
+	// An easing registered with RegisterEasing
+	TypeCustom CurveType = 7
 )
 
 type Curve struct {
-	curveType         CurveType
-	constraints       [4]float64
-	lastCubicSolution float64
+	curveType   CurveType
+	constraints [4]float64
+	// Name and easing of a TypeCustom curve, the easing is nil if it's not registered
+	name   string
+	easing Easing
 }
 
 func MakeCurve() *Curve {
@@ -29,59 +30,114 @@ func MakeCurve() *Curve {
 
 func MakeCurveWithType(curveType CurveType) *Curve {
 	return &Curve{
-		curveType:   curveType,
+		curveType: curveType,
 	}
 }
 
+// MakeCurveWithName returns a curve with the SCML curve or the registered easing with the given name.
+// Unknown names give a curve playing as linear, which keeps the name so that saving the model writes it back.
+func MakeCurveWithName(name string) *Curve {
+	c := MakeCurve()
+	if err := c.SetEasing(name); err != nil && name != "" {
+		c.curveType, c.name = TypeCustom, name
+	}
+	return c
+}
+
 func (c *Curve) String() string {
-	return fmt.Sprintf("Curve [c1:%f, c2:%f, c3%f, c4%f]", c.constraints[0], c.constraints[1], c.constraints[2], c.constraints[3])
+	return fmt.Sprintf("Curve [%s, c1:%f, c2:%f, c3%f, c4%f]", c.Name(), c.constraints[0], c.constraints[1], c.constraints[2], c.constraints[3])
+}
+
+func (c *Curve) GetType() CurveType {
+	return c.curveType
 }
 
-func getCurveTypeFromName(name string) CurveType {
+// Name returns the name of the curve, as used by the curve_type attribute
+func (c *Curve) Name() string {
+	if c.curveType == TypeCustom {
+		return c.name
+	}
+	return getCurveTypeName(c.curveType)
+}
+
+// SetEasing changes the curve to the SCML curve or the registered easing with the given name.
+// The curve is left unchanged if there is none.
+func (c *Curve) SetEasing(name string) error {
+	if curveType, ok := getBuiltinCurveType(name); ok {
+		c.curveType, c.name, c.easing = curveType, "", nil
+		return nil
+	}
+	easing := GetEasing(name)
+	if easing == nil {
+		return fmt.Errorf("unknown easing '%s'", name)
+	}
+	c.curveType, c.name, c.easing = TypeCustom, name, easing
+	return nil
+}
+
+func (c *Curve) GetConstraints() [4]float64 {
+	return c.constraints
+}
+
+func (c *Curve) SetConstraints(c1 float64, c2 float64, c3 float64, c4 float64) *Curve {
+	c.constraints = [4]float64{c1, c2, c3, c4}
+	return c
+}
+
+func getBuiltinCurveType(name string) (CurveType, bool) {
 	switch name {
+	case "linear":
+		return TypeLinear, true
 	case "instant":
-		return TypeInstant
+		return TypeInstant, true
 	case "quadratic":
-		return TypeQuadratic
+		return TypeQuadratic, true
 	case "cubic":
-		return TypeCubic
+		return TypeCubic, true
 	case "quartic":
-		return TypeQuartic
+		return TypeQuartic, true
 	case "quintic":
-		return TypeQuintic
+		return TypeQuintic, true
 	case "bezier":
-		return TypeBezier
+		return TypeBezier, true
 	default:
-		return TypeLinear
+		return TypeLinear, false
 	}
 }
 
-func (c *Curve) interpolate(a float64, b float64, t float64) float64 {
-	switch c.curveType {
+func getCurveTypeName(curveType CurveType) string {
+	switch curveType {
 	case TypeInstant:
-		return a
-	case TypeLinear:
-		return Linear(a, b, t)
+		return "instant"
 	case TypeQuadratic:
-		return Quadratic(a, Linear(a, b, c.constraints[0]), b, t)
+		return "quadratic"
 	case TypeCubic:
-		return Cubic(a, Linear(a, b, c.constraints[0]), Linear(a, b, c.constraints[1]), b, t)
+		return "cubic"
 	case TypeQuartic:
-		return Quartic(a, Linear(a, b, c.constraints[0]), Linear(a, b, c.constraints[1]), Linear(a, b, c.constraints[2]), b, t)
+		return "quartic"
 	case TypeQuintic:
-		return Quintic(a, Linear(a, b, c.constraints[0]), Linear(a, b, c.constraints[1]), Linear(a, b, c.constraints[2]), Linear(a, b, c.constraints[3]), b, t)
+		return "quintic"
 	case TypeBezier:
-		cubicSolution := solveCubic(3*(c.constraints[0]-c.constraints[2])+1, 3*(c.constraints[2]-2*c.constraints[0]), 3*c.constraints[0], -t)
-		if cubicSolution == -1 {
-			cubicSolution = c.lastCubicSolution
-		} else {
-			c.lastCubicSolution = cubicSolution
-		}
-		return Linear(a, b, Bezier(cubicSolution, 0, c.constraints[1], c.constraints[3], 1))
-
+		return "bezier"
 	default:
-		return Linear(a, b, t)
+		return "linear"
+	}
+}
+
+// ease returns the fraction of the change between two keys applied at t
+func (c *Curve) ease(t float64) float64 {
+	easing := c.easing
+	if c.curveType != TypeCustom {
+		easing = builtinEasings[c.curveType]
+	}
+	if easing == nil {
+		return t
 	}
+	return easing.Ease(t, c.constraints)
+}
+
+func (c *Curve) interpolate(a float64, b float64, t float64) float64 {
+	return Linear(a, b, c.ease(t))
 }
 
 func (c *Curve) interpolatePoints(a *Point, b *Point, t float64, target *Point) {
@@ -89,46 +145,17 @@ func (c *Curve) interpolatePoints(a *Point, b *Point, t float64, target *Point)
 	target[1] = c.interpolate(a.Y(), b.Y(), t)
 }
 
+// interpolateAngleWithSpin interpolates two angles, in radians, rotating in the direction given by spin
+// (1 counterclockwise, -1 clockwise). With spin 0 the angle doesn't change until the next key.
+// The curve eases the rotation like any other value.
 func (c *Curve) interpolateAngleWithSpin(a float64, b float64, t float64, spin int) float64 {
 	if spin == 0 {
 		return a
 	}
-	if spin > 0 {
-		if b-a < 0 {
-			b += math.Pi*2
-		}
-	} else if spin < 0 {
-		if b-a > 0 {
-			b -= math.Pi*2
-		}
-	}
-
-	return c.interpolate(a, b, t)
+	return a + c.interpolate(0, spinDifference(a, b, spin), t)
 }
 
+// interpolateAngle interpolates two angles, in radians, along the shortest path
 func (c *Curve) interpolateAngle(a float64, b float64, t float64) float64 {
-	switch c.curveType {
-	case TypeInstant:
-		return a
-	case TypeLinear:
-		return LinearAngle(a, b, t)
-	case TypeQuadratic:
-		return QuadraticAngle(a, LinearAngle(a, b, c.constraints[0]), b, t)
-	case TypeCubic:
-		return CubicAngle(a, LinearAngle(a, b, c.constraints[0]), LinearAngle(a, b, c.constraints[1]), b, t)
-	case TypeQuartic:
-		return QuarticAngle(a, LinearAngle(a, b, c.constraints[0]), LinearAngle(a, b, c.constraints[1]), LinearAngle(a, b, c.constraints[2]), b, t)
-	case TypeQuintic:
-		return QuinticAngle(a, LinearAngle(a, b, c.constraints[0]), LinearAngle(a, b, c.constraints[1]), LinearAngle(a, b, c.constraints[2]), LinearAngle(a, b, c.constraints[3]), b, t)
-	case TypeBezier:
-		cubicSolution := solveCubic(3*(c.constraints[0]-c.constraints[2])+1, 3*(c.constraints[2]-2*c.constraints[0]), 3*c.constraints[0], -t)
-		if cubicSolution == -1 {
-			cubicSolution = c.lastCubicSolution
-		} else {
-			c.lastCubicSolution = cubicSolution
-		}
-		return LinearAngle(a, b, Bezier(cubicSolution, 0, c.constraints[1], c.constraints[3], 1))
-	default:
-		return LinearAngle(a, b, t)
-	}
+	return a + c.interpolate(0, angleDifference(b, a), t)
 }
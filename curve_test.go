@@ -0,0 +1,101 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterpolateAngleWithSpin(t *testing.T) {
+	linear := MakeCurve()
+	quadratic := MakeCurveWithType(TypeQuadratic).SetConstraints(0.2, 0, 0, 0)
+	tests := []struct {
+		name  string
+		curve *Curve
+		from  float64
+		to    float64
+		spin  int
+		t     float64
+		// In degrees, compared modulo 360
+		expected float64
+	}{
+		{"ccw across 0", linear, 350, 10, 1, 0.5, 0},
+		{"cw the long way across 0", linear, 350, 10, -1, 0.5, 180},
+		{"ccw the long way across 0", linear, 10, 350, 1, 0.5, 180},
+		{"cw across 0", linear, 10, 350, -1, 0.5, 0},
+		{"cw across 0, quarter", linear, 10, 350, -1, 0.25, 5},
+		{"full turn is no rotation", linear, 0, 360, 1, 0.5, 0},
+		{"same angle cw", linear, 90, 90, -1, 0.5, 90},
+		{"half turn ccw", linear, 0, 180, 1, 0.5, 90},
+		{"half turn cw", linear, 0, 180, -1, 0.5, 270},
+		{"unnormalized start", linear, 720, 10, 1, 0.5, 5},
+		{"negative start", linear, -10, 10, 1, 0.5, 0},
+		{"end of the interval", linear, 350, 10, 1, 1, 10},
+		{"spin 0 keeps the angle", linear, 10, 20, 0, 0.5, 10},
+		{"spin 0 keeps the angle at the end", linear, 350, 10, 0, 1, 350},
+		// Quadratic with c1=0.2 eases 0.5 to 0.35
+		{"quadratic ccw across 0", quadratic, 350, 10, 1, 0.5, 357},
+		{"quadratic cw the long way", quadratic, 350, 10, -1, 0.5, 350 - 0.35*340},
+		{"quadratic ccw the long way", quadratic, 10, 350, 1, 0.5, 10 + 0.35*340},
+		{"quadratic cw across 0", quadratic, 10, 350, -1, 0.5, 3},
+		{"quadratic spin 0", quadratic, 10, 350, 0, 0.5, 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			angle := test.curve.interpolateAngleWithSpin(test.from*math.Pi/180, test.to*math.Pi/180, test.t, test.spin)
+			degrees := angle * 180 / math.Pi
+			if math.Abs(math.Remainder(degrees-test.expected, 360)) > 1e-9 {
+				t.Fatalf("expected %g, got %g", test.expected, degrees)
+			}
+		})
+	}
+}
+
+func TestInterpolateAngle(t *testing.T) {
+	tests := []struct {
+		from     float64
+		to       float64
+		expected float64
+	}{
+		{350, 10, 0},
+		{10, 350, 0},
+		{0, 180, 90},
+		{170, -170, 180},
+		{-170, 170, 180},
+		{720, 10, 5},
+	}
+	for _, test := range tests {
+		angle := MakeCurve().interpolateAngle(test.from*math.Pi/180, test.to*math.Pi/180, 0.5) * 180 / math.Pi
+		if math.Abs(math.Remainder(angle-test.expected, 360)) > 1e-9 {
+			t.Errorf("%g -> %g: expected %g, got %g", test.from, test.to, test.expected, angle)
+		}
+	}
+}
+
+func TestAngleDifference(t *testing.T) {
+	// Rotations going from `from` to `to`, along the shortest path and in the direction of the spin
+	tests := []struct {
+		from     float64
+		to       float64
+		spin     int
+		shortest float64
+		spun     float64
+	}{
+		{350, 10, 1, 20, 20},
+		{350, 10, -1, 20, -340},
+		{10, 350, 1, -20, 340},
+		{10, 350, -1, -20, -20},
+		{360, 0, 1, 0, 0},
+		{0, 90, -1, 90, -270},
+		{0, 720, -1, 0, 0},
+		{350, 10, 0, 20, 0},
+	}
+	for _, test := range tests {
+		from, to := test.from*math.Pi/180, test.to*math.Pi/180
+		if shortest := angleDifference(to, from) * 180 / math.Pi; math.Abs(shortest-test.shortest) > 1e-9 {
+			t.Errorf("angleDifference(%g, %g): expected %g, got %g", test.to, test.from, test.shortest, shortest)
+		}
+		if spun := spinDifference(from, to, test.spin) * 180 / math.Pi; math.Abs(spun-test.spun) > 1e-9 {
+			t.Errorf("spinDifference(%g, %g, %d): expected %g, got %g", test.from, test.to, test.spin, test.spun, spun)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package spriter
+
+import (
+	"image"
+	"math"
+)
+
+// DebugFlags selects which elements are produced by the debug drawing
+type DebugFlags int
+
+const (
+	DebugBones DebugFlags = 1 << iota
+	DebugBoxes
+	DebugPoints
+	DebugPivots
+	DebugAll = DebugBones | DebugBoxes | DebugPoints | DebugPivots
+)
+
+type DebugPrimitiveType int
+
+const (
+	// Open sequence of segments
+	DebugLines DebugPrimitiveType = iota
+	// Closed outline
+	DebugPolygon
+)
+
+// DebugPrimitive is a shape to draw, in world coordinates
+type DebugPrimitive struct {
+	Type DebugPrimitiveType
+	// The kind of element it represents: one of DebugBones, DebugBoxes, DebugPoints, DebugPivots
+	Element DebugFlags
+	// Name of the timeline of the element
+	Name   string
+	Points []Point
+	Color  Color
+}
+
+var (
+	DebugBoneColor  = MakeColor(1, 0.8, 0, 1)
+	DebugBoxColor   = MakeColor(1, 0.2, 0.2, 1)
+	DebugPointColor = MakeColor(0.2, 1, 0.2, 1)
+	DebugPivotColor = MakeColor(0, 0.8, 1, 1)
+)
+
+// Size, in world units, of the markers for points and pivots
+const debugMarkerSize = 6.0
+
+// GetDebugPrimitives returns the shapes of the bones, boxes, points and sprite pivots of the current pose.
+// Bones are drawn as tapered shapes, using the width and height of their obj_info.
+// Sprites hidden by a swap have no pivot marker.
+func (p *EntityPlayer) GetDebugPrimitives(flags DebugFlags) []DebugPrimitive {
+	primitives := make([]DebugPrimitive, 0)
+	if flags&DebugBones != 0 {
+		for i := range p.currentKey.BoneRefs {
+			ref := p.currentKey.BoneRefs[i]
+			bone := p.unmappedInterpolatedKeys[ref.Timeline].object
+			timeline := p.animation.Timelines[ref.Timeline]
+			primitives = append(primitives, DebugPrimitive{
+				Type:    DebugPolygon,
+				Element: DebugBones,
+				Name:    timeline.Name,
+				Points:  makeBoneShape(bone, timeline.objectInfo),
+				Color:   DebugBoneColor,
+			})
+		}
+	}
+
+	for _, index := range p.GetDrawOrder() {
+		ref := p.currentKey.ObjectRefs[index]
+		object := p.unmappedInterpolatedKeys[ref.Timeline].object
+		name := p.animation.Timelines[ref.Timeline].Name
+		switch {
+		case object.objectType == TypeBox && flags&DebugBoxes != 0:
+			corners, _ := p.getObjectCorners(object)
+			primitives = append(primitives, DebugPrimitive{
+				Type:    DebugPolygon,
+				Element: DebugBoxes,
+				Name:    name,
+				Points:  corners[:],
+				Color:   DebugBoxColor,
+			})
+		case object.objectType == TypePoint && flags&DebugPoints != 0:
+			// A diamond on the point and a line showing its direction
+			direction := MakePoint(debugMarkerSize*2*signum(object.Scale.X()), 0)
+			direction.Rotate(object.Angle)
+			direction.Add(object.Position)
+			primitives = append(primitives,
+				DebugPrimitive{
+					Type:    DebugPolygon,
+					Element: DebugPoints,
+					Name:    name,
+					Points:  makeMarker(object.Position, debugMarkerSize/2),
+					Color:   DebugPointColor,
+				},
+				DebugPrimitive{
+					Type:    DebugLines,
+					Element: DebugPoints,
+					Name:    name,
+					Points:  []Point{*object.Position, *direction},
+					Color:   DebugPointColor,
+				},
+			)
+		case object.objectType == TypeSprite && flags&DebugPivots != 0 && p.GetMappedFileIndexForKeyObject(object) != -1:
+			size := debugMarkerSize / 2
+			x, y := object.Position.X(), object.Position.Y()
+			primitives = append(primitives,
+				DebugPrimitive{
+					Type:    DebugLines,
+					Element: DebugPivots,
+					Name:    name,
+					Points:  []Point{{x - size, y}, {x + size, y}},
+					Color:   DebugPivotColor,
+				},
+				DebugPrimitive{
+					Type:    DebugLines,
+					Element: DebugPivots,
+					Name:    name,
+					Points:  []Point{{x, y - size}, {x, y + size}},
+					Color:   DebugPivotColor,
+				},
+			)
+		}
+	}
+	return primitives
+}
+
+// makeBoneShape returns a kite going from the origin of the bone to its tip, as wide as the bone
+func makeBoneShape(bone *TimelineKeyObject, info *ObjectInfo) []Point {
+	length := info.Width
+	width := info.Height
+	if width <= 0 {
+		width = 10
+	}
+	joint := math.Min(width/2, length/2)
+	shape := []Point{{0, 0}, {joint, width / 2}, {length, 0}, {joint, -width / 2}}
+	for i := range shape {
+		shape[i].Scale(bone.Scale)
+		shape[i].Rotate(bone.Angle)
+		shape[i].Add(bone.Position)
+	}
+	return shape
+}
+
+func makeMarker(center *Point, size float64) []Point {
+	x, y := center.X(), center.Y()
+	return []Point{{x - size, y}, {x, y + size}, {x + size, y}, {x, y - size}}
+}
+
+// RenderDebug draws the outlines of the primitives, on top of the current content of the target
+func (r *SoftwareRenderer) RenderDebug(primitives []DebugPrimitive) {
+	for i := range primitives {
+		primitive := &primitives[i]
+		points := primitive.Points
+		for j := 0; j+1 < len(points); j++ {
+			r.drawLine(&points[j], &points[j+1], primitive.Color)
+		}
+		if primitive.Type == DebugPolygon && len(points) > 2 {
+			r.drawLine(&points[len(points)-1], &points[0], primitive.Color)
+		}
+	}
+}
+
+// drawLine draws a one pixel wide line between two points in world coordinates
+func (r *SoftwareRenderer) drawLine(from *Point, to *Point, c Color) {
+	x0, y0 := r.View.Apply(from.X(), from.Y())
+	x1, y1 := r.View.Apply(to.X(), to.Y())
+	steps := int(math.Ceil(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))))
+	bounds := r.Target.Bounds()
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		x := int(math.Floor(Linear(x0, x1, t)))
+		y := int(math.Floor(Linear(y0, y1, t)))
+		if !(image.Point{X: x, Y: y}).In(bounds) {
+			continue
+		}
+		offset := r.Target.PixOffset(x, y)
+		pix := r.Target.Pix[offset : offset+4 : offset+4]
+		pix[0] = blend(c.R*c.A, pix[0], c.A)
+		pix[1] = blend(c.G*c.A, pix[1], c.A)
+		pix[2] = blend(c.B*c.A, pix[2], c.A)
+		pix[3] = blend(c.A, pix[3], c.A)
+	}
+}
@@ -0,0 +1,144 @@
+package spriter
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+const debugDrawTestSCML = `<spriter_data scml_version="1.0">
+    <folder id="0">
+        <file id="0" name="body.png" width="10" height="10" pivot_x="0.5" pivot_y="0.5"/>
+    </folder>
+    <entity id="0" name="Debug">
+        <obj_info name="arm" type="bone" w="40" h="8"/>
+        <obj_info name="hitbox" type="box" w="20" h="10"/>
+        <animation id="0" name="idle" length="1000">
+            <mainline>
+                <key id="0">
+                    <bone_ref id="0" timeline="0" key="0"/>
+                    <object_ref id="0" timeline="1" key="0" z_index="0"/>
+                    <object_ref id="1" timeline="2" key="0" z_index="1"/>
+                    <object_ref id="2" timeline="3" key="0" z_index="2"/>
+                    <object_ref id="3" timeline="4" key="0" z_index="3"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="arm" object_type="bone">
+                <key id="0"><bone x="10" y="0" angle="0"/></key>
+            </timeline>
+            <timeline id="1" name="hitbox" object_type="box">
+                <key id="0"><object x="0" y="0" pivot_x="0" pivot_y="0" angle="0"/></key>
+            </timeline>
+            <timeline id="2" name="hand" object_type="point">
+                <key id="0"><object x="5" y="5" angle="90"/></key>
+            </timeline>
+            <timeline id="3" name="body">
+                <key id="0"><object folder="0" file="0" x="30" y="20" angle="0"/></key>
+            </timeline>
+            <timeline id="4" name="cape">
+                <key id="0"><object folder="0" file="0" x="-30" y="-20" angle="0"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+func checkPoints(t *testing.T, name string, actual []Point, expected []Point) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		return
+	}
+	for i := range expected {
+		if math.Abs(actual[i].X()-expected[i].X()) > 1e-9 || math.Abs(actual[i].Y()-expected[i].Y()) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+			return
+		}
+	}
+}
+
+func getDebugPrimitives(primitives []DebugPrimitive, element DebugFlags, name string) []DebugPrimitive {
+	found := make([]DebugPrimitive, 0)
+	for _, primitive := range primitives {
+		if primitive.Element == element && primitive.Name == name {
+			found = append(found, primitive)
+		}
+	}
+	return found
+}
+
+func TestGetDebugPrimitives(t *testing.T) {
+	model := loadTestModel(t, debugDrawTestSCML)
+	p := MakeEntityPlayer(model.Entities[0])
+	p.HideObjectSprite("cape")
+	p.Update(0)
+	primitives := p.GetDebugPrimitives(DebugAll)
+
+	// The bone goes from its origin to its length, the width of the obj_info, as wide as its height
+	bone := getDebugPrimitives(primitives, DebugBones, "arm")
+	if len(bone) != 1 || bone[0].Type != DebugPolygon || bone[0].Color != DebugBoneColor {
+		t.Fatalf("expected the shape of the bone, got %v", bone)
+	}
+	checkPoints(t, "bone", bone[0].Points, []Point{{10, 0}, {14, 4}, {50, 0}, {14, -4}})
+
+	box := getDebugPrimitives(primitives, DebugBoxes, "hitbox")
+	if len(box) != 1 || box[0].Type != DebugPolygon {
+		t.Fatalf("expected the outline of the box, got %v", box)
+	}
+	checkPoints(t, "box", box[0].Points, []Point{{0, 0}, {20, 0}, {20, 10}, {0, 10}})
+
+	point := getDebugPrimitives(primitives, DebugPoints, "hand")
+	if len(point) != 2 || point[0].Type != DebugPolygon || point[1].Type != DebugLines {
+		t.Fatalf("expected the marker and the direction of the point, got %v", point)
+	}
+	checkPoints(t, "point marker", point[0].Points, []Point{{2, 5}, {5, 8}, {8, 5}, {5, 2}})
+	// The point is rotated by 90 degrees: its direction goes up
+	checkPoints(t, "point direction", point[1].Points, []Point{{5, 5}, {5, 5 + 2*debugMarkerSize}})
+
+	pivot := getDebugPrimitives(primitives, DebugPivots, "body")
+	if len(pivot) != 2 {
+		t.Fatalf("expected a cross on the pivot of the sprite, got %v", pivot)
+	}
+	checkPoints(t, "pivot", pivot[0].Points, []Point{{27, 20}, {33, 20}})
+	checkPoints(t, "pivot", pivot[1].Points, []Point{{30, 17}, {30, 23}})
+
+	if hidden := getDebugPrimitives(primitives, DebugPivots, "cape"); len(hidden) != 0 {
+		t.Errorf("hidden sprites must be skipped, got %v", hidden)
+	}
+	if len(primitives) != 6 {
+		t.Errorf("expected 6 primitives, got %d", len(primitives))
+	}
+
+	for _, flags := range []DebugFlags{DebugBones, DebugBoxes, DebugPoints, DebugPivots} {
+		for _, primitive := range p.GetDebugPrimitives(flags) {
+			if primitive.Element != flags {
+				t.Errorf("flags %d: unexpected primitive of %s", flags, primitive.Name)
+			}
+		}
+	}
+}
+
+func TestRenderDebug(t *testing.T) {
+	renderer := MakeSoftwareRenderer(&Model{}, image.NewRGBA(image.Rect(0, 0, 20, 20)))
+	renderer.RenderDebug([]DebugPrimitive{
+		{Type: DebugPolygon, Points: []Point{{-5, -5}, {5, -5}, {5, 5}}, Color: MakeColor(1, 0, 0, 1)},
+	})
+	// The view puts the origin in the center, Y going up
+	for _, test := range []struct {
+		x, y  int
+		drawn bool
+	}{
+		{5, 15, true},
+		{10, 15, true},
+		{15, 15, true},
+		{15, 10, true},
+		// The polygon is closed
+		{10, 10, true},
+		{5, 5, false},
+		{12, 14, false},
+	} {
+		c := renderer.Target.RGBAAt(test.x, test.y)
+		if drawn := c.R == 255 && c.A == 255 && c.G == 0; drawn != test.drawn {
+			t.Errorf("pixel (%d, %d): expected drawn %v, got %v", test.x, test.y, test.drawn, c)
+		}
+	}
+}
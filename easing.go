@@ -0,0 +1,156 @@
+package spriter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Easing maps the progress between two keys, from 0 to 1, to the fraction of the change applied.
+// The constraints are the c1..c4 attributes of the key, each easing uses them as it needs.
+type Easing interface {
+	Ease(t float64, constraints [4]float64) float64
+}
+
+// EasingFunc allows to use a function as an Easing
+type EasingFunc func(t float64, constraints [4]float64) float64
+
+func (f EasingFunc) Ease(t float64, constraints [4]float64) float64 {
+	return f(t, constraints)
+}
+
+// The curves of the SCML format
+var builtinEasings = map[CurveType]Easing{
+	TypeLinear: EasingFunc(func(t float64, c [4]float64) float64 {
+		return t
+	}),
+	TypeInstant: EasingFunc(func(t float64, c [4]float64) float64 {
+		return 0
+	}),
+	TypeQuadratic: EasingFunc(func(t float64, c [4]float64) float64 {
+		return Quadratic(0, c[0], 1, t)
+	}),
+	TypeCubic: EasingFunc(func(t float64, c [4]float64) float64 {
+		return Cubic(0, c[0], c[1], 1, t)
+	}),
+	TypeQuartic: EasingFunc(func(t float64, c [4]float64) float64 {
+		return Quartic(0, c[0], c[1], c[2], 1, t)
+	}),
+	TypeQuintic: EasingFunc(func(t float64, c [4]float64) float64 {
+		return Quintic(0, c[0], c[1], c[2], c[3], 1, t)
+	}),
+	// (c1, c2) and (c3, c4) are the control points of a bezier going from (0, 0) to (1, 1)
+	TypeBezier: EasingFunc(func(t float64, c [4]float64) float64 {
+		return Bezier(solveBezier(c[0], c[2], t), 0, c[1], c[3], 1)
+	}),
+}
+
+// Easings which can be used by name in the curve_type attribute, besides the curves of the SCML format.
+// Models can be loaded in several goroutines while easings are registered.
+var easingsMutex sync.RWMutex
+var easings = map[string]Easing{
+	"elastic": EasingFunc(ElasticEasing),
+	"bounce":  EasingFunc(BounceEasing),
+	"steps":   EasingFunc(StepsEasing),
+	"hermite": EasingFunc(HermiteEasing),
+}
+
+// RegisterEasing makes an easing available by name, to the loader and to Curve.SetEasing.
+// The names of the SCML curves can't be replaced. Easings must be registered before loading the models using them:
+// the curves of the keys are resolved when loading.
+func RegisterEasing(name string, easing Easing) error {
+	if name == "" || easing == nil {
+		return errors.New("easing: name and easing are required")
+	}
+	if _, ok := getBuiltinCurveType(name); ok {
+		return fmt.Errorf("easing: '%s' is a builtin curve", name)
+	}
+	easingsMutex.Lock()
+	easings[name] = easing
+	easingsMutex.Unlock()
+	return nil
+}
+
+// GetEasing returns the easing with the given name, builtin or registered, nil if there is none
+func GetEasing(name string) Easing {
+	if curveType, ok := getBuiltinCurveType(name); ok {
+		return builtinEasings[curveType]
+	}
+	easingsMutex.RLock()
+	defer easingsMutex.RUnlock()
+	return easings[name]
+}
+
+// EasingNames returns the names of the registered easings, sorted. The SCML curves are not included.
+func EasingNames() []string {
+	easingsMutex.RLock()
+	names := make([]string, 0, len(easings))
+	for name := range easings {
+		names = append(names, name)
+	}
+	easingsMutex.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// ElasticEasing overshoots the target and oscillates around it. c1 is the period of the oscillations
+// (0.3 when not set).
+func ElasticEasing(t float64, c [4]float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	if t >= 1 {
+		return 1
+	}
+	period := c[0]
+	if period <= 0 {
+		period = 0.3
+	}
+	return 1 + math.Pow(2, -10*t)*math.Sin((t-period/4)*2*math.Pi/period)
+}
+
+// BounceEasing reaches the target and bounces back three times, with decreasing height
+func BounceEasing(t float64, c [4]float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// StepsEasing changes the value in c1 equal steps (1 when not set), the last one reaching the target
+func StepsEasing(t float64, c [4]float64) float64 {
+	return MakeStepsEasing(int(math.Round(c[0]))).Ease(t, c)
+}
+
+// MakeStepsEasing returns an easing with a fixed number of steps, e.g. to register "steps4"
+func MakeStepsEasing(steps int) Easing {
+	if steps < 1 {
+		steps = 1
+	}
+	return EasingFunc(func(t float64, c [4]float64) float64 {
+		if t >= 1 {
+			return 1
+		}
+		return math.Floor(math.Max(0, t)*float64(steps)) / float64(steps)
+	})
+}
+
+// HermiteEasing is a cubic Hermite spline from 0 to 1, c1 and c2 are the tangents at the start and at the end.
+// With both tangents at 0 it's a smoothstep.
+func HermiteEasing(t float64, c [4]float64) float64 {
+	t2 := t * t
+	t3 := t2 * t
+	return (t3-2*t2+t)*c[0] + (-2*t3 + 3*t2) + (t3-t2)*c[1]
+}
@@ -0,0 +1,41 @@
+package spriter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+var testEasingNames int
+
+// makeTestEasingName returns a name not registered yet: easings stay registered when the tests are run again
+func makeTestEasingName(prefix string) string {
+	testEasingNames++
+	return fmt.Sprintf("%s-%d", prefix, testEasingNames)
+}
+
+func TestRegisterEasingWhileLoading(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := RegisterEasing(fmt.Sprintf("test-steps%d", i), MakeStepsEasing(i+1)); err != nil {
+				t.Error(err)
+			}
+			EasingNames()
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := DecodeModel([]byte(testHeroSCML), FormatSCML); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 4; i++ {
+		if GetEasing(fmt.Sprintf("test-steps%d", i)) == nil {
+			t.Errorf("test-steps%d is not registered", i)
+		}
+	}
+}
@@ -11,16 +11,31 @@ type Entity struct {
 	MaxNumTimelines  int
 	animationPointer int
 	namedAnimations  map[string]*Animation
+	model            *Model
 }
 
 func (e *Entity) String() string {
 	return fmt.Sprintf("id: %d, name:%s", e.Id, e.Name)
 }
 
+// GetModel returns the model the entity belongs to
+func (e *Entity) GetModel() *Model {
+	return e.model
+}
+
 func (e *Entity) getAnimationByIndex(index int) *Animation {
 	return e.Animations[index]
 }
 
+func (e *Entity) hasAnimation(animation *Animation) bool {
+	for i := range e.Animations {
+		if e.Animations[i] == animation {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Entity) getAnimationByName(name string) *Animation {
 	if e.namedAnimations == nil {
 		e.namedAnimations = make(map[string]*Animation)
@@ -98,12 +113,12 @@ func MakeObjectInfo(name string, t ObjectType, w float64, h float64) *ObjectInfo
 }
 
 func (oi *ObjectInfo) String() string {
-	return fmt.Sprintf("[name: %s, type: %s, size: %dx%d", oi.Name, oi.Type, oi.Width, oi.Height)
+	return fmt.Sprintf("[name: %s, type: %s, size: %gx%g", oi.Name, oi.Type, oi.Width, oi.Height)
 }
 
 type CharacterMap struct {
 	Id           int                  `xml:"id,attr"`
-	Name         string               `xml:"name"`
+	Name         string               `xml:"name,attr"`
 	Maps         []mapInstructionData `xml:"map"`
 	FilesMapping map[int]int
 }
@@ -2,6 +2,7 @@ package spriter
 
 import (
 	"fmt"
+	"sort"
 )
 
 type PlayerListenerInterface interface {
@@ -35,7 +36,16 @@ type EntityPlayer struct {
 	previousKey          *MainlineKey
 	listeners            []PlayerListenerInterface
 	objToTimeline        map[*TimelineKeyObject]*TimelineKey
-	enabledCharacterMaps map[string]*CharacterMap
+	enabledCharacterMaps []*CharacterMap
+	objectSwaps          map[string]spriteSwap
+	fileSwaps            map[int]spriteSwap
+	boneOverrides        map[string]*BoneOverride
+	ikChains             []*IKChain
+	constraints          []*LookAtConstraint
+	rootMotion           *RootMotion
+	animationBounds      map[*Animation]map[BoundsFlags]*Rect
+	tint                 Color
+	timeDelta            int
 }
 
 func (p *EntityPlayer) String() string {
@@ -47,9 +57,14 @@ func MakeEntityPlayer(entity *Entity) *EntityPlayer {
 	p.root = MakeTimelineKeyBone()
 	p.position = MakePoint(0, 0)
 	p.pivot = MakePoint(0, 0)
+	p.scale = 1
+	p.tint = White
 	p.listeners = make([]PlayerListenerInterface, 0)
 	p.objToTimeline = make(map[*TimelineKeyObject]*TimelineKey)
-	p.enabledCharacterMaps = make(map[string]*CharacterMap)
+	p.enabledCharacterMaps = make([]*CharacterMap, 0)
+	p.objectSwaps = make(map[string]spriteSwap)
+	p.fileSwaps = make(map[int]spriteSwap)
+	p.boneOverrides = make(map[string]*BoneOverride)
 	p.setEntity(entity)
 	return p
 }
@@ -61,6 +76,8 @@ func (p *EntityPlayer) Update(timeDeltaMs int) {
 	if p.rootIsDirty {
 		p.updateRoot()
 	}
+	p.timeDelta = timeDeltaMs
+	p.updateRootMotion()
 	p.animation.update(p.time, p.root)
 	p.currentKey = p.animation.currentKey
 	if p.previousKey != p.currentKey {
@@ -78,6 +95,8 @@ func (p *EntityPlayer) Update(timeDeltaMs int) {
 		p.interpolatedKeys[i].object.setWithBone(p.animation.interpolatedKeys[i].object)
 		p.unmappedInterpolatedKeys[i].object.setWithBone(p.animation.unmappedInterpolatedKeys[i].object)
 	}
+	p.removeRootMotion()
+	p.resolvePose()
 
 	for i := range p.listeners {
 		p.listeners[i].afterUpdate(p)
@@ -87,17 +106,28 @@ func (p *EntityPlayer) Update(timeDeltaMs int) {
 
 func (p *EntityPlayer) increaseTime(millisecs int) {
 	p.time += millisecs
-	if p.time > p.animation.Length {
+	if p.animation.Length <= 0 {
+		p.time = 0
+		return
+	}
+	// A long update can loop several times
+	for p.time > p.animation.Length {
 		p.time = p.time - p.animation.Length
+		if p.rootMotion != nil {
+			p.rootMotion.wraps++
+		}
 		for i := range p.listeners {
 			p.listeners[i].animationFinished(p.animation)
 		}
 	}
-	if p.time < 0 {
+	for p.time < 0 {
 		for i := range p.listeners {
 			p.listeners[i].animationFinished(p.animation)
 		}
 		p.time += p.animation.Length
+		if p.rootMotion != nil {
+			p.rootMotion.wraps--
+		}
 	}
 }
 
@@ -172,12 +202,7 @@ func (p *EntityPlayer) unmapObjects(base *ObjectRef) {
 		if ref.ParentRef != base && base != nil {
 			continue
 		}
-		parent := p.unmappedInterpolatedKeys[ref.ParentRef.Timeline].object
-		if ref.ParentRef == nil {
-			parent = p.root
-		}
-		p.unmappedInterpolatedKeys[ref.Timeline].object.setWithBone(p.interpolatedKeys[ref.Timeline].object)
-		p.unmappedInterpolatedKeys[ref.Timeline].object.unmapCoordinates(parent)
+		p.unmapRef(ref)
 		p.unmapObjects(ref)
 	}
 	for i := range p.currentKey.ObjectRefs {
@@ -185,12 +210,7 @@ func (p *EntityPlayer) unmapObjects(base *ObjectRef) {
 		if ref.ParentRef != base && base != nil {
 			continue
 		}
-		parent := p.unmappedInterpolatedKeys[ref.ParentRef.Timeline].object
-		if ref.ParentRef == nil {
-			parent = p.root
-		}
-		p.unmappedInterpolatedKeys[ref.Timeline].object.setWithBone(p.interpolatedKeys[ref.Timeline].object)
-		p.unmappedInterpolatedKeys[ref.Timeline].object.unmapCoordinates(parent)
+		p.unmapRef(ref)
 	}
 }
 
@@ -241,6 +261,9 @@ func (p *EntityPlayer) setAnimation(animation *Animation) {
 		p.time = 0
 	}
 	p.animation = animation
+	if p.rootMotion != nil {
+		p.rootMotion.reset()
+	}
 	tempTime := p.time
 	p.time = 0
 	p.Update(0)
@@ -273,6 +296,9 @@ func (p *EntityPlayer) getTime() int {
 func (p *EntityPlayer) setTime(time int) *EntityPlayer {
 	p.time = time
 	p.increaseTime(0)
+	if p.rootMotion != nil {
+		p.rootMotion.reset()
+	}
 	return p
 }
 
@@ -347,12 +373,47 @@ func (p *EntityPlayer) setPivot(x float64, y float64) *EntityPlayer {
 	return p
 }
 
-func (p *EntityPlayer) EnableCharacterMap(mapName string) {
-	p.enabledCharacterMaps[mapName] = p.entity.getCharacterMap(mapName)
+// EnableCharacterMap puts a character map on top of the enabled ones, moving it there if it's already enabled.
+// When several maps remap the same file, the one on top wins. An error is returned if the entity has no map
+// with the given name.
+func (p *EntityPlayer) EnableCharacterMap(mapName string) error {
+	characterMap := p.entity.getCharacterMap(mapName)
+	if characterMap == nil {
+		return fmt.Errorf("character map '%s' not found in entity '%s'", mapName, p.entity.Name)
+	}
+	p.removeCharacterMap(mapName)
+	p.enabledCharacterMaps = append(p.enabledCharacterMaps, characterMap)
+	p.animationBounds = nil
+	return nil
 }
 
 func (p *EntityPlayer) DisableCharacterMap(mapName string) {
-	delete(p.enabledCharacterMaps, mapName)
+	p.removeCharacterMap(mapName)
+	p.animationBounds = nil
+}
+
+// ClearCharacterMaps disables all the character maps
+func (p *EntityPlayer) ClearCharacterMaps() {
+	p.enabledCharacterMaps = p.enabledCharacterMaps[:0]
+	p.animationBounds = nil
+}
+
+// GetEnabledCharacterMaps returns the names of the enabled character maps, from the bottom to the top one
+func (p *EntityPlayer) GetEnabledCharacterMaps() []string {
+	names := make([]string, len(p.enabledCharacterMaps))
+	for i, characterMap := range p.enabledCharacterMaps {
+		names[i] = characterMap.Name
+	}
+	return names
+}
+
+func (p *EntityPlayer) removeCharacterMap(mapName string) {
+	for i, characterMap := range p.enabledCharacterMaps {
+		if characterMap.Name == mapName {
+			p.enabledCharacterMaps = append(p.enabledCharacterMaps[:i], p.enabledCharacterMaps[i+1:]...)
+			return
+		}
+	}
 }
 
 // Helper function for drawing the sprite
@@ -366,14 +427,93 @@ func (p *EntityPlayer) GetKeyObjectToDraw(index int) *TimelineKeyObject {
 	return p.unmappedInterpolatedKeys[objRef.Timeline].object
 }
 
+// GetDrawOrder returns the indices of the objects to draw, sorted by z_index from back to front
+func (p *EntityPlayer) GetDrawOrder() []int {
+	refs := p.currentKey.ObjectRefs
+	order := make([]int, len(refs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return refs[order[i]].zIndex() < refs[order[j]].zIndex()
+	})
+	return order
+}
+
+// GetMappedFileIndexForKeyObject returns the index of the file to draw for an object, after applying the
+// character maps and the sprite swaps. -1 means that the object is hidden, see ExternalFileIndex for the
+// indices of external textures.
 func (p *EntityPlayer) GetMappedFileIndexForKeyObject(object *TimelineKeyObject) int {
-	fileIndex := object.fileIndex
-	for _, v := range p.enabledCharacterMaps {
-		if _, ok := v.FilesMapping[fileIndex]; ok {
-			fileIndex = v.FilesMapping[fileIndex]
+	fileIndex, _, _ := p.resolveFile(object)
+	return fileIndex
+}
+
+// mapFileIndex returns the file replacing the given one and the character map replacing it, nil if none does.
+// Maps are keyed on the original file: the top map remapping it wins, remapped files are not mapped again.
+func (p *EntityPlayer) mapFileIndex(fileIndex int) (int, *CharacterMap) {
+	for i := len(p.enabledCharacterMaps) - 1; i >= 0; i-- {
+		characterMap := p.enabledCharacterMaps[i]
+		if mapped, ok := characterMap.FilesMapping[fileIndex]; ok {
+			return mapped, characterMap
 		}
 	}
-	return fileIndex
+	return fileIndex, nil
+}
+
+// GetMappedFileForKeyObject returns the file to draw for an object, nil if it's hidden.
+// The file of an external texture is the one given to the swap.
+func (p *EntityPlayer) GetMappedFileForKeyObject(object *TimelineKeyObject) *File {
+	_, file, _ := p.resolveFile(object)
+	return file
+}
+
+// resolveFile applies the character maps, then the sprite swaps
+func (p *EntityPlayer) resolveFile(object *TimelineKeyObject) (int, *File, *CharacterMap) {
+	fileIndex, characterMap := p.mapFileIndex(object.fileIndex)
+	if swap, ok := p.getSpriteSwap(object, fileIndex); ok {
+		if swap.file != nil {
+			return swap.fileIndex, swap.file, characterMap
+		}
+		fileIndex = swap.fileIndex
+	}
+	if p.entity.model == nil {
+		return fileIndex, nil, characterMap
+	}
+	return fileIndex, p.entity.model.GetFile(fileIndex), characterMap
+}
+
+// ObjectFile is the file drawn for an object of the current key
+type ObjectFile struct {
+	Name     string
+	Original *File
+	// nil if the object is hidden
+	File *File
+	// Name of the character map replacing the original file, empty if none does
+	CharacterMap string
+	// Index of the file drawn, see GetMappedFileIndexForKeyObject
+	FileIndex int
+}
+
+// GetObjectFiles returns the files drawn for the sprites of the current key, in the order of the object refs,
+// after applying the character maps and the sprite swaps
+func (p *EntityPlayer) GetObjectFiles() []ObjectFile {
+	files := make([]ObjectFile, 0, len(p.currentKey.ObjectRefs))
+	for _, ref := range p.currentKey.ObjectRefs {
+		key := p.unmappedInterpolatedKeys[ref.Timeline]
+		if key.object.objectType != TypeSprite {
+			continue
+		}
+		fileIndex, file, characterMap := p.resolveFile(key.object)
+		objectFile := ObjectFile{Name: p.animation.Timelines[ref.Timeline].Name, File: file, FileIndex: fileIndex}
+		if p.entity.model != nil {
+			objectFile.Original = p.entity.model.GetFile(key.object.fileIndex)
+		}
+		if characterMap != nil {
+			objectFile.CharacterMap = characterMap.Name
+		}
+		files = append(files, objectFile)
+	}
+	return files
 }
 
 func (p *EntityPlayer) SetBone(name string, x float64, y float64, angle float64, scaleX float64, scaleY float64) {
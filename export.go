@@ -0,0 +1,393 @@
+package spriter
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type ExportOptions struct {
+	// Frames per second
+	FrameRate float64
+	Scale     float64
+	// Removes the transparent borders of each frame
+	Trim          bool
+	CharacterMaps []string
+}
+
+func MakeExportOptions() *ExportOptions {
+	return &ExportOptions{
+		FrameRate: 30,
+		Scale:     1,
+		Trim:      true,
+	}
+}
+
+// Times are in milliseconds: a higher frame rate would give frames at the same time
+const maxExportFrameRate = 1000
+
+func (o *ExportOptions) validate(entity *Entity) error {
+	if !(o.FrameRate > 0) || o.FrameRate > maxExportFrameRate {
+		return fmt.Errorf("invalid frame rate %g, it must be above 0 and at most %d", o.FrameRate, maxExportFrameRate)
+	}
+	if !(o.Scale > 0) || math.IsInf(o.Scale, 0) {
+		return fmt.Errorf("invalid scale %g", o.Scale)
+	}
+	for _, name := range o.CharacterMaps {
+		if entity.getCharacterMap(name) == nil {
+			return fmt.Errorf("character map '%s' not found in entity '%s'", name, entity.Name)
+		}
+	}
+	return nil
+}
+
+// ExportedFrame is a rendered frame of an animation. All the frames of an export share the same
+// canvas: SourceRect tells where the (possibly trimmed) image is placed in it.
+type ExportedFrame struct {
+	Image      *image.RGBA
+	Time       int
+	Duration   int
+	SourceRect image.Rectangle
+}
+
+// ExportedAnimation contains the frames of an animation and the position of the origin of the entity
+// (the anchor) in the canvas shared by all the frames
+type ExportedAnimation struct {
+	Entity     string
+	Animation  string
+	Looping    bool
+	FrameRate  float64
+	Scale      float64
+	CanvasSize image.Point
+	Anchor     image.Point
+	Frames     []*ExportedFrame
+}
+
+type ManifestRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type ManifestPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type ManifestFrame struct {
+	Filename string `json:"filename,omitempty"`
+	// Rectangle of the frame in the sprite sheet (or in its own file)
+	Frame ManifestRect `json:"frame"`
+	// Rectangle of the frame in the canvas, before trimming
+	SpriteSourceSize ManifestRect `json:"spriteSourceSize"`
+	// Position of the anchor relative to the top-left corner of Frame
+	Anchor   ManifestPoint `json:"anchor"`
+	Time     int           `json:"time"`
+	Duration int           `json:"duration"`
+}
+
+// FrameManifest describes the exported frames, it's written as JSON next to the images
+type FrameManifest struct {
+	Image      string          `json:"image,omitempty"`
+	Entity     string          `json:"entity"`
+	Animation  string          `json:"animation"`
+	Looping    bool            `json:"looping"`
+	FrameRate  float64         `json:"frameRate"`
+	Scale      float64         `json:"scale"`
+	SourceSize ManifestPoint   `json:"sourceSize"`
+	Anchor     ManifestPoint   `json:"anchor"`
+	Frames     []ManifestFrame `json:"frames"`
+}
+
+// getFrameTimes returns the times sampled at the given frame rate. The last pose of the animation
+// is included only if it doesn't loop, since it's the same as the first one otherwise.
+func getFrameTimes(animation *Animation, frameRate float64) []int {
+	if animation.Length <= 0 || frameRate <= 0 {
+		return []int{0}
+	}
+	times := make([]int, 0)
+	step := 1000 / frameRate
+	for i := 0; ; i++ {
+		t := int(math.Round(float64(i) * step))
+		if t >= animation.Length {
+			break
+		}
+		// Above 1000 fps several frames would round to the same time
+		if len(times) > 0 && t == times[len(times)-1] {
+			continue
+		}
+		times = append(times, t)
+	}
+	if !animation.Looping && times[len(times)-1] != animation.Length {
+		times = append(times, animation.Length)
+	}
+	return times
+}
+
+// ExportAnimation renders every frame of an animation with the SoftwareRenderer.
+// A nil options uses MakeExportOptions.
+func ExportAnimation(entity *Entity, animationName string, options *ExportOptions) (*ExportedAnimation, error) {
+	if options == nil {
+		options = MakeExportOptions()
+	}
+	if err := options.validate(entity); err != nil {
+		return nil, err
+	}
+	animation := entity.getAnimationByName(animationName)
+	if animation == nil {
+		return nil, fmt.Errorf("animation '%s' not found in entity '%s'", animationName, entity.Name)
+	}
+	if entity.model == nil {
+		return nil, fmt.Errorf("entity '%s' doesn't belong to a model", entity.Name)
+	}
+	player := MakeEntityPlayer(entity)
+	player.SetScale(options.Scale)
+	for i := range options.CharacterMaps {
+		if err := player.EnableCharacterMap(options.CharacterMaps[i]); err != nil {
+			return nil, err
+		}
+	}
+	player.setAnimation(animation)
+	times := getFrameTimes(animation, options.FrameRate)
+
+	// The canvas contains all the frames, so that the anchor is the same for each of them.
+	// Only the images drawn by the frames are loaded.
+	bounds := MakeEmptyRect()
+	drawn := make(map[int]bool)
+	var commands []DrawCommand
+	for i := range times {
+		player.time = times[i]
+		player.Update(0)
+		bounds.Union(player.GetAABB(BoundsSprites))
+		commands = player.AppendDrawCommands(commands[:0])
+		for j := range commands {
+			drawn[commands[j].FileIndex] = true
+		}
+	}
+	if bounds.IsEmpty() {
+		bounds.AddPoint(0, 0)
+	}
+	// One pixel of margin for the bilinear filtering
+	minX := int(math.Floor(bounds.MinX)) - 1
+	maxX := int(math.Ceil(bounds.MaxX)) + 1
+	minY := int(math.Floor(bounds.MinY)) - 1
+	maxY := int(math.Ceil(bounds.MaxY)) + 1
+	exported := &ExportedAnimation{
+		Entity:     entity.Name,
+		Animation:  animation.Name,
+		Looping:    animation.Looping,
+		FrameRate:  options.FrameRate,
+		Scale:      options.Scale,
+		CanvasSize: image.Pt(maxX-minX, maxY-minY),
+		Anchor:     image.Pt(-minX, maxY),
+	}
+
+	canvas := image.NewRGBA(image.Rectangle{Max: exported.CanvasSize})
+	renderer := MakeSoftwareRenderer(entity.model, canvas)
+	renderer.View = Matrix{1, 0, 0, -1, float64(exported.Anchor.X), float64(exported.Anchor.Y)}
+	for fileIndex := range drawn {
+		if err := renderer.loadFileImage(fileIndex); err != nil {
+			return nil, err
+		}
+	}
+	for i := range times {
+		player.time = times[i]
+		player.Update(0)
+		draw.Draw(canvas, canvas.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		player.Draw(renderer)
+
+		rect := canvas.Bounds()
+		if options.Trim {
+			rect = opaqueBounds(canvas)
+		}
+		frameImage := image.NewRGBA(image.Rectangle{Max: rect.Size()})
+		draw.Draw(frameImage, frameImage.Bounds(), canvas, rect.Min, draw.Src)
+
+		duration := animation.Length - times[i]
+		if i+1 < len(times) {
+			duration = times[i+1] - times[i]
+		} else if duration <= 0 {
+			duration = int(math.Round(1000 / options.FrameRate))
+		}
+		exported.Frames = append(exported.Frames, &ExportedFrame{
+			Image:      frameImage,
+			Time:       times[i],
+			Duration:   duration,
+			SourceRect: rect,
+		})
+	}
+	return exported, nil
+}
+
+// opaqueBounds returns the smallest rectangle containing all the non transparent pixels.
+// Fully transparent images give a 1x1 rectangle.
+func opaqueBounds(img *image.RGBA) image.Rectangle {
+	bounds := img.Bounds()
+	rect := image.Rectangle{Min: bounds.Max, Max: bounds.Min}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if img.Pix[img.PixOffset(x, y)+3] == 0 {
+				continue
+			}
+			rect.Min.X = minInt(rect.Min.X, x)
+			rect.Min.Y = minInt(rect.Min.Y, y)
+			rect.Max.X = maxInt(rect.Max.X, x+1)
+			rect.Max.Y = maxInt(rect.Max.Y, y+1)
+		}
+	}
+	if rect.Empty() {
+		return image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+1, bounds.Min.Y+1)
+	}
+	return rect
+}
+
+func (e *ExportedAnimation) makeManifest() *FrameManifest {
+	return &FrameManifest{
+		Entity:     e.Entity,
+		Animation:  e.Animation,
+		Looping:    e.Looping,
+		FrameRate:  e.FrameRate,
+		Scale:      e.Scale,
+		SourceSize: ManifestPoint{e.CanvasSize.X, e.CanvasSize.Y},
+		Anchor:     ManifestPoint{e.Anchor.X, e.Anchor.Y},
+		Frames:     make([]ManifestFrame, 0, len(e.Frames)),
+	}
+}
+
+func (e *ExportedAnimation) makeManifestFrame(frame *ExportedFrame, position image.Point) ManifestFrame {
+	size := frame.SourceRect.Size()
+	return ManifestFrame{
+		Frame:            ManifestRect{position.X, position.Y, size.X, size.Y},
+		SpriteSourceSize: ManifestRect{frame.SourceRect.Min.X, frame.SourceRect.Min.Y, size.X, size.Y},
+		Anchor:           ManifestPoint{e.Anchor.X - frame.SourceRect.Min.X, e.Anchor.Y - frame.SourceRect.Min.Y},
+		Time:             frame.Time,
+		Duration:         frame.Duration,
+	}
+}
+
+// WritePNGSequence writes every frame as <prefix>_0000.png, <prefix>_0001.png... and the manifest
+// as <prefix>.json in the given folder
+func (e *ExportedAnimation) WritePNGSequence(dir string, prefix string) error {
+	manifest := e.makeManifest()
+	for i, frame := range e.Frames {
+		name := fmt.Sprintf("%s_%04d.png", prefix, i)
+		if err := writePNG(filepath.Join(dir, name), frame.Image); err != nil {
+			return err
+		}
+		manifestFrame := e.makeManifestFrame(frame, image.Point{})
+		manifestFrame.Filename = name
+		manifest.Frames = append(manifest.Frames, manifestFrame)
+	}
+	return writeJSON(filepath.Join(dir, prefix+".json"), manifest)
+}
+
+// PackSpriteSheet packs all the frames in a single image, using rows of frames sorted by height
+func (e *ExportedAnimation) PackSpriteSheet(padding int) (*image.RGBA, *FrameManifest) {
+	area := 0
+	maxWidth := 1
+	for _, frame := range e.Frames {
+		size := frame.Image.Bounds().Size()
+		area += (size.X + padding) * (size.Y + padding)
+		maxWidth = maxInt(maxWidth, size.X)
+	}
+	sheetWidth := maxInt(maxWidth, int(math.Ceil(math.Sqrt(float64(area)))))
+
+	order := make([]int, len(e.Frames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return e.Frames[order[i]].Image.Bounds().Dy() > e.Frames[order[j]].Image.Bounds().Dy()
+	})
+
+	positions := make([]image.Point, len(e.Frames))
+	x, y, rowHeight := 0, 0, 0
+	for _, index := range order {
+		size := e.Frames[index].Image.Bounds().Size()
+		if x > 0 && x+size.X > sheetWidth {
+			x = 0
+			y += rowHeight + padding
+			rowHeight = 0
+		}
+		positions[index] = image.Pt(x, y)
+		x += size.X + padding
+		rowHeight = maxInt(rowHeight, size.Y)
+	}
+
+	sheetHeight := 0
+	usedWidth := 0
+	for i, frame := range e.Frames {
+		size := frame.Image.Bounds().Size()
+		sheetHeight = maxInt(sheetHeight, positions[i].Y+size.Y)
+		usedWidth = maxInt(usedWidth, positions[i].X+size.X)
+	}
+	sheet := image.NewRGBA(image.Rect(0, 0, usedWidth, sheetHeight))
+	manifest := e.makeManifest()
+	for i, frame := range e.Frames {
+		rect := image.Rectangle{Min: positions[i], Max: positions[i].Add(frame.Image.Bounds().Size())}
+		draw.Draw(sheet, rect, frame.Image, image.Point{}, draw.Src)
+		manifest.Frames = append(manifest.Frames, e.makeManifestFrame(frame, positions[i]))
+	}
+	return sheet, manifest
+}
+
+// WriteSpriteSheet packs the frames and writes the sprite sheet and its JSON manifest
+func (e *ExportedAnimation) WriteSpriteSheet(imagePath string, manifestPath string, padding int) error {
+	sheet, manifest := e.PackSpriteSheet(padding)
+	if err := writePNG(imagePath, sheet); err != nil {
+		return err
+	}
+	manifest.Image = filepath.Base(imagePath)
+	return writeJSON(manifestPath, manifest)
+}
+
+func writePNG(path string, img image.Image) error {
+	return writeFile(path, func(w io.Writer) error {
+		return png.Encode(w, img)
+	})
+}
+
+// writeFile creates a file and fills it with `write`. The error of Close is returned too: it can mean that
+// the data has not been written completely, e.g. when the disk is full.
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeJSON(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
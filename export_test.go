@@ -0,0 +1,89 @@
+package spriter
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestImages writes an opaque image for each file of the model, in a temporary folder used as its dir
+func writeTestImages(t *testing.T, model *Model) {
+	t.Helper()
+	model.dir = t.TempDir()
+	for _, file := range model.Files {
+		path := filepath.Join(model.dir, file.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		img := image.NewRGBA(image.Rect(0, 0, file.Width, file.Height))
+		for i := range img.Pix {
+			img.Pix[i] = 0xff
+		}
+		if err := writePNG(path, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExportAnimationOptions(t *testing.T) {
+	model := loadTestModel(t, testHeroSCML)
+	writeTestImages(t, model)
+	entity := model.Entities[0]
+
+	exported, err := ExportAnimation(entity, "idle", nil)
+	if err != nil {
+		t.Fatalf("nil options must use the defaults: %v", err)
+	}
+	if exported.FrameRate != 30 || exported.Scale != 1 {
+		t.Errorf("expected the default frame rate and scale, got %g and %g", exported.FrameRate, exported.Scale)
+	}
+
+	for _, options := range []*ExportOptions{
+		{},
+		{FrameRate: 30},
+		{Scale: 1},
+		{FrameRate: -1, Scale: 1},
+		{FrameRate: 30, Scale: -2},
+		{FrameRate: 1001, Scale: 1},
+		{FrameRate: 30, Scale: 1, CharacterMaps: []string{"armd"}},
+	} {
+		if _, err := ExportAnimation(entity, "idle", options); err == nil {
+			t.Errorf("expected an error with frame rate %g, scale %g and maps %v", options.FrameRate, options.Scale, options.CharacterMaps)
+		}
+	}
+}
+
+func TestGetFrameTimes(t *testing.T) {
+	animation := &Animation{Length: 100, Looping: true}
+	for _, frameRate := range []float64{1, 30, 60, 999, 1000, 5000} {
+		times := getFrameTimes(animation, frameRate)
+		for i := 1; i < len(times); i++ {
+			if times[i] <= times[i-1] {
+				t.Fatalf("%g fps: frame %d at %d ms is not after frame %d at %d ms", frameRate, i, times[i], i-1, times[i-1])
+			}
+		}
+	}
+	if times := getFrameTimes(animation, 1000); len(times) != 100 {
+		t.Errorf("expected a frame per millisecond at 1000 fps, got %d frames", len(times))
+	}
+}
+
+func TestExportLoadsOnlyDrawnImages(t *testing.T) {
+	model := loadTestModel(t, testHeroSCML)
+	writeTestImages(t, model)
+	// The sword is drawn only with the "armed" character map
+	if err := os.Remove(filepath.Join(model.GetDir(), "parts/sword.png")); err != nil {
+		t.Fatal(err)
+	}
+	entity := model.Entities[0]
+
+	if _, err := ExportAnimation(entity, "walk", nil); err != nil {
+		t.Errorf("images which are not drawn must not be loaded: %v", err)
+	}
+	options := MakeExportOptions()
+	options.CharacterMaps = []string{"armed"}
+	if _, err := ExportAnimation(entity, "walk", options); err == nil {
+		t.Error("expected an error for the missing image of the sword")
+	}
+}
@@ -0,0 +1,475 @@
+package spriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is a file format models can be read from and written to
+type Format int
+
+const (
+	FormatSCML Format = iota
+	FormatSCON
+	// The binary format of binary.go, with the .sprb extension
+	FormatBinary
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatSCON:
+		return "scon"
+	case FormatBinary:
+		return "binary"
+	default:
+		return "scml"
+	}
+}
+
+// ParseFormat returns the format with the given name or extension, e.g. "scml"
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "scml":
+		return FormatSCML, nil
+	case "scon":
+		return FormatSCON, nil
+	case "sprb", "binary":
+		return FormatBinary, nil
+	}
+	return FormatSCML, fmt.Errorf("unknown format '%s'", name)
+}
+
+// GetFormatFromFileName returns the format matching the extension of the file
+func GetFormatFromFileName(fileName string) (Format, error) {
+	return ParseFormat(filepath.Ext(fileName))
+}
+
+// DecodeModel reads a model in the given format and prepares it for playing.
+// The names of the files are relative to the current folder.
+func DecodeModel(data []byte, format Format) (*Model, error) {
+	if format == FormatBinary {
+		return DecodeBinaryModel(data)
+	}
+	if format == FormatSCON {
+		var err error
+		data, err = sconToSCML(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	model, err := decodeSCML(data)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// decodeSCML returns the model even when the data is invalid, with what has been parsed before the error
+func decodeSCML(data []byte) (*Model, error) {
+	model := &Model{}
+	err := xml.Unmarshal(data, model)
+	initializeData(model)
+	return model, err
+}
+
+// EncodeModel writes the model in the given format. The data comes from the runtime fields, angles are
+// converted back to degrees: a model read, encoded and read again plays the same way.
+func EncodeModel(w io.Writer, model *Model, format Format) error {
+	if format == FormatBinary {
+		return EncodeBinaryModel(w, model)
+	}
+	document := makeModelDocument(model, format)
+	var data []byte
+	var err error
+	if format == FormatSCON {
+		data, err = document.encodeJSON()
+	} else {
+		data, err = document.encodeXML()
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// SaveModel writes the model to a file, in the format matching its extension
+func SaveModel(model *Model, fileName string) error {
+	format, err := GetFormatFromFileName(fileName)
+	if err != nil {
+		return err
+	}
+	return writeFile(fileName, func(w io.Writer) error {
+		return EncodeModel(w, model, format)
+	})
+}
+
+// readModelFile returns the partially parsed model along with the error when a SCML file is invalid,
+// as NewSpriterModelFromFile always did. The model is nil for the other errors.
+func readModelFile(fileName string) (*Model, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	// Unknown extensions are read as SCML
+	format, _ := GetFormatFromFileName(fileName)
+	if IsBinaryModel(data) {
+		format = FormatBinary
+	}
+	var model *Model
+	if format == FormatSCML {
+		model, err = decodeSCML(data)
+	} else {
+		model, err = DecodeModel(data, format)
+	}
+	if model != nil {
+		model.dir = filepath.Dir(fileName)
+	}
+	if err != nil {
+		return model, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return model, nil
+}
+
+// document is the tree shared by the encoders: SCML elements and attributes are SCON objects and values
+type document struct {
+	name     string
+	attrs    []documentAttr
+	children []*document
+}
+
+type documentAttr struct {
+	name string
+	// int, float64, string, bool or json.Number
+	value interface{}
+}
+
+// SCON children which are single objects instead of arrays
+var sconObjects = map[string]bool{"mainline": true, "bone": true, "object": true}
+
+func (d *document) attr(name string, value interface{}) *document {
+	d.attrs = append(d.attrs, documentAttr{name, value})
+	return d
+}
+
+func (d *document) child(name string) *document {
+	c := &document{name: name}
+	d.children = append(d.children, c)
+	return c
+}
+
+func (d *document) curveAttrs(c *Curve) {
+	if c == nil || c.curveType == TypeLinear {
+		return
+	}
+	d.attr("curve_type", c.Name())
+	for i, name := range []string{"c1", "c2", "c3", "c4"} {
+		if c.constraints[i] != 0 {
+			d.attr(name, c.constraints[i])
+		}
+	}
+}
+
+func makeModelDocument(m *Model, format Format) *document {
+	root := &document{name: "spriter_data"}
+	if format == FormatSCON {
+		version := m.SconVersion
+		if version == "" {
+			version = "1.0"
+		}
+		root.attr("scon_version", version)
+	} else {
+		root.attr("scml_version", "1.0")
+	}
+	root.attr("generator", m.Generator).attr("generator_version", m.GeneratorVersion)
+
+	for _, folder := range m.Folders {
+		f := root.child("folder").attr("id", folder.Id)
+		if folder.Name != "" {
+			f.attr("name", folder.Name)
+		}
+		for _, file := range folder.Files {
+			f.child("file").attr("id", file.Id).attr("name", file.Name).
+				attr("width", file.Width).attr("height", file.Height).
+				attr("pivot_x", file.PivotX).attr("pivot_y", file.PivotY)
+		}
+	}
+
+	for _, entity := range m.Entities {
+		e := root.child("entity").attr("id", entity.Id).attr("name", entity.Name)
+		for _, info := range entity.ObjectInfos {
+			e.child("obj_info").attr("name", info.Name).attr("type", string(info.Type)).
+				attr("w", info.Width).attr("h", info.Height)
+		}
+		for _, characterMap := range entity.CharacterMaps {
+			c := e.child("character_map").attr("id", characterMap.Id).attr("name", characterMap.Name)
+			for _, mapping := range characterMap.Maps {
+				mapDocument := c.child("map").attr("folder", mapping.Folder).attr("file", mapping.File)
+				if mapping.TargetFolder != nil && mapping.TargetFile != nil {
+					mapDocument.attr("target_folder", *mapping.TargetFolder).attr("target_file", *mapping.TargetFile)
+				}
+			}
+		}
+		for _, animation := range entity.Animations {
+			addAnimationDocument(e, animation)
+		}
+	}
+	return root
+}
+
+func addAnimationDocument(parent *document, animation *Animation) {
+	a := parent.child("animation").attr("id", animation.Id).attr("name", animation.Name).
+		attr("length", animation.Length).attr("interval", animation.Interval)
+	if !animation.Looping {
+		a.attr("looping", false)
+	}
+
+	mainline := a.child("mainline")
+	for _, key := range animation.Mainline.Keys {
+		k := mainline.child("key").attr("id", key.Id)
+		if key.Time != 0 {
+			k.attr("time", key.Time)
+		}
+		k.curveAttrs(key.curve)
+		for _, ref := range key.BoneRefs {
+			addRefDocument(k.child("bone_ref"), ref)
+		}
+		for _, ref := range key.ObjectRefs {
+			r := k.child("object_ref")
+			addRefDocument(r, ref)
+			if z, err := strconv.Atoi(ref.ZIndex); err == nil {
+				r.attr("z_index", z)
+			} else if ref.ZIndex != "" {
+				r.attr("z_index", ref.ZIndex)
+			}
+		}
+	}
+
+	for _, timeline := range animation.Timelines {
+		t := a.child("timeline").attr("id", timeline.Id).attr("name", timeline.Name)
+		if timeline.ObjectType != TypeSprite {
+			t.attr("object_type", string(timeline.ObjectType))
+		}
+		for _, key := range timeline.Keys {
+			k := t.child("key").attr("id", key.Id)
+			if key.Time != 0 {
+				k.attr("time", key.Time)
+			}
+			if key.Spin != 1 {
+				k.attr("spin", key.Spin)
+			}
+			k.curveAttrs(key.Curve)
+			addKeyObjectDocument(k, key.object)
+		}
+	}
+}
+
+func addRefDocument(r *document, ref *ObjectRef) {
+	r.attr("id", ref.Id)
+	if ref.Parent != nil {
+		r.attr("parent", *ref.Parent)
+	}
+	r.attr("timeline", ref.Timeline).attr("key", ref.Key)
+}
+
+func addKeyObjectDocument(parent *document, object *TimelineKeyObject) {
+	var o *document
+	if object.objectType == TypeBone {
+		o = parent.child("bone")
+	} else {
+		o = parent.child("object")
+	}
+	if object.objectType == TypeSprite {
+		o.attr("folder", object.Folder).attr("file", object.File)
+	}
+	o.attr("x", object.Position.X()).attr("y", object.Position.Y())
+	// Only the pivots of the keys are written, the others come from the files
+	if object.XMLPivotX != nil || object.XMLPivotY != nil {
+		o.attr("pivot_x", object.Pivot.X()).attr("pivot_y", object.Pivot.Y())
+	}
+	o.attr("angle", object.Angle*180/math.Pi)
+	if object.Scale.X() != 1 {
+		o.attr("scale_x", object.Scale.X())
+	}
+	if object.Scale.Y() != 1 {
+		o.attr("scale_y", object.Scale.Y())
+	}
+	if object.Alpha != 1 {
+		o.attr("a", object.Alpha)
+	}
+}
+
+// formatNumber rounds the value to 9 decimals, removing the noise of the conversions between degrees and radians
+func formatNumber(value float64) string {
+	value = math.Round(value*1e9) / 1e9
+	if value == 0 {
+		// No negative zeros
+		value = 0
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return formatNumber(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (d *document) encodeXML() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+	encoder.Indent("", "    ")
+	if err := d.writeXML(encoder); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes(), nil
+}
+
+func (d *document) writeXML(encoder *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: d.name}}
+	for _, attr := range d.attrs {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr.name}, Value: formatValue(attr.value)})
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, child := range d.children {
+		if err := child.writeXML(encoder); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+func (d *document) encodeJSON() ([]byte, error) {
+	var compact bytes.Buffer
+	if err := d.writeJSON(&compact); err != nil {
+		return nil, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", "\t"); err != nil {
+		return nil, err
+	}
+	indented.WriteString("\n")
+	return indented.Bytes(), nil
+}
+
+func (d *document) writeJSON(buffer *bytes.Buffer) error {
+	buffer.WriteString("{")
+	first := true
+	separator := func() {
+		if !first {
+			buffer.WriteString(",")
+		}
+		first = false
+	}
+	for _, attr := range d.attrs {
+		separator()
+		name, _ := json.Marshal(attr.name)
+		buffer.Write(name)
+		buffer.WriteString(":")
+		var value []byte
+		var err error
+		if f, ok := attr.value.(float64); ok {
+			value = []byte(formatNumber(f))
+		} else {
+			value, err = json.Marshal(attr.value)
+		}
+		if err != nil {
+			return err
+		}
+		buffer.Write(value)
+	}
+
+	// Children with the same name are grouped in an array, in the order of their first appearance
+	groups := make(map[string][]*document)
+	names := make([]string, 0)
+	for _, child := range d.children {
+		if _, ok := groups[child.name]; !ok {
+			names = append(names, child.name)
+		}
+		groups[child.name] = append(groups[child.name], child)
+	}
+	for _, name := range names {
+		separator()
+		fmt.Fprintf(buffer, "%q:", name)
+		if sconObjects[name] {
+			if err := groups[name][0].writeJSON(buffer); err != nil {
+				return err
+			}
+			continue
+		}
+		buffer.WriteString("[")
+		for i, child := range groups[name] {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			if err := child.writeJSON(buffer); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("]")
+	}
+	buffer.WriteString("}")
+	return nil
+}
+
+// sconToSCML turns a SCON document into the equivalent SCML, so that it can be read by the SCML loader
+func sconToSCML(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root map[string]interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+	return makeJSONDocument("spriter_data", root).encodeXML()
+}
+
+func makeJSONDocument(name string, object map[string]interface{}) *document {
+	d := &document{name: name}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch v := object[key].(type) {
+		case map[string]interface{}:
+			d.children = append(d.children, makeJSONDocument(key, v))
+		case []interface{}:
+			for _, item := range v {
+				// Arrays of values (e.g. tags) are not part of the model
+				if child, ok := item.(map[string]interface{}); ok {
+					d.children = append(d.children, makeJSONDocument(key, child))
+				}
+			}
+		case nil:
+		default:
+			d.attr(key, v)
+		}
+	}
+	return d
+}
@@ -0,0 +1,132 @@
+package spriter
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func encodeTestModel(t *testing.T, model *Model, format Format) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := EncodeModel(&buffer, model, format); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestSCONRoundTrip(t *testing.T) {
+	model := loadTestModel(t, testHeroSCML)
+	scml := encodeTestModel(t, model, FormatSCML)
+
+	fromSCON, err := DecodeModel(encodeTestModel(t, model, FormatSCON), FormatSCON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip := encodeTestModel(t, fromSCON, FormatSCML)
+	if !bytes.Equal(scml, roundTrip) {
+		t.Errorf("SCML -> SCON -> SCML changed the model:\n%s\n---\n%s", scml, roundTrip)
+	}
+
+	result := loadTestModel(t, string(roundTrip))
+	if differences := ComparePoses(model, result, 60, 1e-9); len(differences) != 0 {
+		t.Errorf("expected the same poses, got %d differences, first: %s", len(differences), differences[0].String())
+	}
+	for _, name := range []string{"walk", "idle"} {
+		a := model.Entities[0].getAnimationByName(name)
+		b := result.Entities[0].getAnimationByName(name)
+		if b == nil || a.Looping != b.Looping || a.Length != b.Length || a.Interval != b.Interval {
+			t.Errorf("animation '%s' changed", name)
+		}
+	}
+	if result.Entities[0].getCharacterMap("armed") == nil {
+		t.Error("the character map is lost")
+	}
+	key := result.Entities[0].getAnimationByName("walk").Mainline.Keys[1]
+	if key.curve.Name() != "quadratic" || key.curve.constraints[0] != 0.2 {
+		t.Errorf("expected the quadratic curve of the mainline key, got %s %v", key.curve.Name(), key.curve.constraints)
+	}
+}
+
+func TestSconToSCML(t *testing.T) {
+	scon := `{
+		"scon_version": "1.0",
+		"folder": [{"id": 0, "file": [{"id": 0, "name": "a.png", "width": 10, "height": 20, "pivot_x": 0.25, "pivot_y": 1e-1}]}],
+		"entity": [{"id": 0, "name": "E", "animation": [{"id": 0, "name": "run", "length": 300, "looping": false,
+			"mainline": {"key": [{"id": 0, "object_ref": [{"id": 0, "timeline": 0, "key": 0, "z_index": 0}]}]},
+			"timeline": [{"id": 0, "name": "t", "key": [{"id": 0, "object": {"folder": 0, "file": 0, "x": 1.5, "angle": 45}}]}]
+		}]}],
+		"tags": ["ignored", "values"],
+		"unused": null
+	}`
+	data, err := sconToSCML([]byte(scon))
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := string(data)
+	for _, expected := range []string{
+		`<spriter_data scon_version="1.0">`,
+		`<file height="20" id="0" name="a.png" pivot_x="0.25" pivot_y="1e-1" width="10"></file>`,
+		`<animation id="0" length="300" looping="false" name="run">`,
+		`<object angle="45" file="0" folder="0" x="1.5"></object>`,
+	} {
+		if !strings.Contains(xml, expected) {
+			t.Errorf("expected %s in\n%s", expected, xml)
+		}
+	}
+	if strings.Contains(xml, "tags") || strings.Contains(xml, "unused") {
+		t.Errorf("arrays of values and nulls must be skipped:\n%s", xml)
+	}
+
+	model, err := DecodeModel([]byte(scon), FormatSCON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	animation := model.Entities[0].getAnimationByName("run")
+	if animation == nil || animation.Looping || animation.Length != 300 {
+		t.Fatal("expected the non looping animation 'run'")
+	}
+	object := animation.Timelines[0].Keys[0].object
+	if object.Position.X() != 1.5 || math.Abs(object.Angle-radians(45)) > 1e-9 || model.Files[0].PivotY != 0.1 {
+		t.Errorf("unexpected values: x %g, angle %g, pivot %g", object.Position.X(), object.Angle, model.Files[0].PivotY)
+	}
+
+	if _, err := sconToSCML([]byte(`{"folder": [`)); err == nil {
+		t.Error("expected an error for an invalid SCON")
+	}
+}
+
+func TestComparePosesReportsDifferences(t *testing.T) {
+	model := loadTestModel(t, testHeroSCML)
+	if differences := ComparePoses(model, model, 60, 1e-9); len(differences) != 0 {
+		t.Fatalf("a model must have the same poses as itself, got %v", differences)
+	}
+
+	altered := loadTestModel(t, strings.Replace(testHeroSCML, `<bone x="60" y="0" angle="20"/>`, `<bone x="60" y="0" angle="25"/>`, 1))
+	differences := ComparePoses(model, altered, 10, 0.001)
+	if len(differences) == 0 {
+		t.Fatal("expected the angle of the lower bone to differ")
+	}
+	found := false
+	for _, difference := range differences {
+		if difference.Animation != "walk" {
+			t.Errorf("only the walk animation is altered, got %s", difference.String())
+		}
+		if difference.Timeline == "lower" && difference.Property == "angle" {
+			found = true
+			if math.Abs(difference.Delta-5) > 1e-6 {
+				t.Errorf("expected a 5 degrees difference, got %s", difference.String())
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a difference of the angle of 'lower', got %v", differences)
+	}
+
+	altered = loadTestModel(t, strings.Replace(testHeroSCML, `name="idle" length="600"`, `name="rest" length="600"`, 1))
+	differences = ComparePoses(model, altered, 10, 0.001)
+	if len(differences) != 1 || differences[0].Animation != "idle" || differences[0].Property != "presence of the animation" {
+		t.Errorf("expected the missing animation, got %v", differences)
+	}
+}
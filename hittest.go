@@ -0,0 +1,101 @@
+package spriter
+
+import (
+	"image"
+	"math"
+)
+
+// AlphaMask gives the opacity, in the range [0,1], of the pixels of the image of a File.
+// (0,0) is the top-left pixel of the image.
+type AlphaMask interface {
+	AlphaAt(x int, y int) float64
+}
+
+// ImageAlphaMask uses the alpha channel of an image as mask
+type ImageAlphaMask struct {
+	Image image.Image
+}
+
+func (m *ImageAlphaMask) AlphaAt(x int, y int) float64 {
+	bounds := m.Image.Bounds()
+	_, _, _, a := m.Image.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+	return float64(a) / 0xffff
+}
+
+type HitResult struct {
+	// The hit object, in world coordinates
+	Object *TimelineKeyObject
+	// Name of the timeline of the object
+	Name string
+	// Index of the object, as used by GetKeyObjectToDraw
+	Index     int
+	FileIndex int
+	// Coordinates of the hit inside the image of the file, (0,0) being the top-left pixel
+	ImageX float64
+	ImageY float64
+}
+
+// HitTest returns the topmost sprite which contains the point (x,y), or nil if there is none.
+// Sprites are tested as rotated rectangles, using the size of their file. Like when drawing, hidden and fully
+// transparent sprites are skipped.
+func (p *EntityPlayer) HitTest(x float64, y float64) *HitResult {
+	return p.HitTestWithAlpha(x, y, nil, 0)
+}
+
+// HitTestWithAlpha works like HitTest but, for the files with an entry in `masks`, a sprite is hit only
+// if the alpha of the pixel under the point is greater than `threshold`.
+// Files are identified by their index, as returned by GetMappedFileIndexForKeyObject.
+func (p *EntityPlayer) HitTestWithAlpha(x float64, y float64, masks map[int]AlphaMask, threshold float64) *HitResult {
+	order := p.GetDrawOrder()
+	for i := len(order) - 1; i >= 0; i-- {
+		index := order[i]
+		object := p.GetKeyObjectToDraw(index)
+		if !isVisibleSprite(object) {
+			continue
+		}
+		fileIndex := p.GetMappedFileIndexForKeyObject(object)
+		file := p.GetMappedFileForKeyObject(object)
+		if file == nil {
+			continue
+		}
+		imageX, imageY, ok := hitSprite(object, file, x, y)
+		if !ok {
+			continue
+		}
+		if mask, ok := masks[fileIndex]; ok && mask != nil {
+			px := int(math.Min(math.Floor(imageX), float64(file.Width-1)))
+			py := int(math.Min(math.Floor(imageY), float64(file.Height-1)))
+			if mask.AlphaAt(px, py) <= threshold {
+				continue
+			}
+		}
+		return &HitResult{
+			Object:    object,
+			Name:      p.animation.Timelines[p.currentKey.ObjectRefs[index].Timeline].Name,
+			Index:     index,
+			FileIndex: fileIndex,
+			ImageX:    imageX,
+			ImageY:    imageY,
+		}
+	}
+	return nil
+}
+
+// hitSprite brings the point in the space of the image of the sprite and checks if it falls inside it
+func hitSprite(object *TimelineKeyObject, file *File, x float64, y float64) (float64, float64, bool) {
+	if object.Scale.X() == 0 || object.Scale.Y() == 0 || file.Width <= 0 || file.Height <= 0 {
+		return 0, 0, false
+	}
+	local := MakePoint(x, y)
+	local.Sub(object.Position)
+	local.Rotate(-object.Angle)
+	local.ScaleCoords(1/object.Scale.X(), 1/object.Scale.Y())
+
+	u := local.X()/float64(file.Width) + object.Pivot.X()
+	v := local.Y()/float64(file.Height) + object.Pivot.Y()
+	if u < 0 || u > 1 || v < 0 || v > 1 {
+		return 0, 0, false
+	}
+	// The Y axis of the images points down
+	return u * float64(file.Width), (1 - v) * float64(file.Height), true
+}
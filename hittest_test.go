@@ -0,0 +1,31 @@
+package spriter
+
+import "testing"
+
+func TestHitTestSkipsInvisibleSprites(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.Update(0)
+	for _, command := range p.GetDrawCommands() {
+		// The center of the sprite, where nothing else is drawn on top in this pose
+		width, height := command.Size()
+		x, y := command.Transform.Apply(width/2, height/2)
+		hit := p.HitTest(x, y)
+		if hit == nil || hit.Name != command.Name {
+			t.Fatalf("expected to hit %s at (%g, %g), got %+v", command.Name, x, y, hit)
+		}
+
+		object := p.GetKeyObjectToDraw(hit.Index)
+		alpha := object.Alpha
+		object.Alpha = 0
+		if hit := p.HitTest(x, y); hit != nil && hit.Name == command.Name {
+			t.Errorf("%s is transparent and must not be hit", command.Name)
+		}
+		object.Alpha = alpha
+
+		p.HideObjectSprite(command.Name)
+		if hit := p.HitTest(x, y); hit != nil && hit.Name == command.Name {
+			t.Errorf("%s is hidden and must not be hit", command.Name)
+		}
+		p.ClearSpriteSwaps()
+	}
+}
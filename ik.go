@@ -0,0 +1,240 @@
+package spriter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type IKSolverType int
+
+const (
+	// Analytic solver for a chain of exactly two bones
+	IKTwoBone IKSolverType = iota
+	// Cyclic Coordinate Descent, for chains of any length
+	IKCCD
+)
+
+// IKChain rotates a chain of bones so that the tip of the last bone reaches a target in world coordinates.
+// Bones are listed by name, from the root of the chain to the end effector. The length of a bone is the
+// width declared in its obj_info.
+type IKChain struct {
+	Name       string
+	Solver     IKSolverType
+	Bones      []string
+	Target     *Point
+	Weight     float64
+	Iterations int
+	Tolerance  float64
+	// Two bones solver only: which side the joint bends to
+	BendPositive bool
+}
+
+func MakeIKChain(name string, solver IKSolverType, bones ...string) *IKChain {
+	return &IKChain{
+		Name:         name,
+		Solver:       solver,
+		Bones:        bones,
+		Target:       MakePoint(0, 0),
+		Weight:       1,
+		Iterations:   10,
+		Tolerance:    0.5,
+		BendPositive: true,
+	}
+}
+
+func (c *IKChain) SetTarget(x float64, y float64) *IKChain {
+	c.Target.SetCoords(x, y)
+	return c
+}
+
+func (c *IKChain) SetWeight(weight float64) *IKChain {
+	c.Weight = weight
+	return c
+}
+
+func (c *IKChain) SetIterations(iterations int) *IKChain {
+	c.Iterations = iterations
+	return c
+}
+
+func (c *IKChain) SetBendPositive(positive bool) *IKChain {
+	c.BendPositive = positive
+	return c
+}
+
+// AddIKChain adds a chain to the player, replacing the one with the same name.
+// Chains are solved in the order they have been added. An error is returned, and the chain is not added,
+// if it has no bones or if a two bones chain doesn't have exactly two.
+func (p *EntityPlayer) AddIKChain(chain *IKChain) error {
+	if err := chain.validate(); err != nil {
+		return err
+	}
+	for i := range p.ikChains {
+		if p.ikChains[i].Name == chain.Name {
+			p.ikChains[i] = chain
+			return nil
+		}
+	}
+	p.ikChains = append(p.ikChains, chain)
+	return nil
+}
+
+func (c *IKChain) validate() error {
+	if c == nil {
+		return errors.New("ik: the chain is nil")
+	}
+	if len(c.Bones) == 0 {
+		return fmt.Errorf("ik: chain '%s' has no bones", c.Name)
+	}
+	if c.Solver == IKTwoBone && len(c.Bones) != 2 {
+		return fmt.Errorf("ik: two bones chain '%s' has %d bones", c.Name, len(c.Bones))
+	}
+	return nil
+}
+
+func (p *EntityPlayer) GetIKChain(name string) *IKChain {
+	for i := range p.ikChains {
+		if p.ikChains[i].Name == name {
+			return p.ikChains[i]
+		}
+	}
+	return nil
+}
+
+func (p *EntityPlayer) RemoveIKChain(name string) {
+	for i := range p.ikChains {
+		if p.ikChains[i].Name == name {
+			p.ikChains = append(p.ikChains[:i], p.ikChains[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *EntityPlayer) resolveIK() {
+	for i := range p.ikChains {
+		p.solveIKChain(p.ikChains[i])
+	}
+}
+
+func (p *EntityPlayer) solveIKChain(chain *IKChain) {
+	weight := math.Max(0, math.Min(1, chain.Weight))
+	if weight == 0 || len(chain.Bones) == 0 {
+		return
+	}
+	// The chain is skipped if any of its bones is not part of the current key
+	indices := make([]int, len(chain.Bones))
+	for i := range chain.Bones {
+		indices[i] = p.getBoneIndex(chain.Bones[i])
+		if indices[i] == -1 {
+			return
+		}
+	}
+	startAngles := make([]float64, len(indices))
+	for i := range indices {
+		startAngles[i] = p.interpolatedKeys[p.currentKey.BoneRefs[indices[i]].Timeline].object.Angle
+	}
+
+	switch chain.Solver {
+	case IKTwoBone:
+		if len(indices) != 2 {
+			return
+		}
+		p.solveTwoBones(indices[0], indices[1], chain)
+	case IKCCD:
+		p.solveCCD(indices, chain)
+	}
+
+	if weight < 1 {
+		for i := range indices {
+			local := p.interpolatedKeys[p.currentKey.BoneRefs[indices[i]].Timeline].object
+			local.Angle = startAngles[i] + math.Remainder(local.Angle-startAngles[i], 2*math.Pi)*weight
+		}
+		p.unmapBonesFrom(indices[0])
+	}
+}
+
+func (p *EntityPlayer) solveTwoBones(upper int, lower int, chain *IKChain) {
+	upperBone := p.getBone(upper)
+	lowerBone := p.getBone(lower)
+	target := chain.Target
+
+	l1 := distance(upperBone.Position, lowerBone.Position)
+	l2 := distance(lowerBone.Position, p.getBoneTip(lower))
+	if l1 == 0 || l2 == 0 {
+		return
+	}
+	d := distance(upperBone.Position, target)
+	d = math.Max(math.Abs(l1-l2)+1e-6, math.Min(l1+l2-1e-6, d))
+
+	// Law of cosines for the angle between the upper segment and the target direction
+	cos := (l1*l1 + d*d - l2*l2) / (2 * l1 * d)
+	bend := math.Acos(math.Max(-1, math.Min(1, cos)))
+	if !chain.BendPositive {
+		bend = -bend
+	}
+	desired := math.Atan2(target.Y()-upperBone.Position.Y(), target.X()-upperBone.Position.X()) + bend
+	current := math.Atan2(lowerBone.Position.Y()-upperBone.Position.Y(), lowerBone.Position.X()-upperBone.Position.X())
+	p.rotateBone(upper, desired-current)
+
+	tip := p.getBoneTip(lower)
+	desired = math.Atan2(target.Y()-lowerBone.Position.Y(), target.X()-lowerBone.Position.X())
+	current = math.Atan2(tip.Y()-lowerBone.Position.Y(), tip.X()-lowerBone.Position.X())
+	p.rotateBone(lower, desired-current)
+}
+
+func (p *EntityPlayer) solveCCD(indices []int, chain *IKChain) {
+	effector := indices[len(indices)-1]
+	target := chain.Target
+	for iteration := 0; iteration < chain.Iterations; iteration++ {
+		for i := len(indices) - 1; i >= 0; i-- {
+			pivot := p.getBone(indices[i]).Position
+			tip := p.getBoneTip(effector)
+			current := math.Atan2(tip.Y()-pivot.Y(), tip.X()-pivot.X())
+			desired := math.Atan2(target.Y()-pivot.Y(), target.X()-pivot.X())
+			p.rotateBone(indices[i], math.Remainder(desired-current, 2*math.Pi))
+		}
+		if distance(p.getBoneTip(effector), target) <= chain.Tolerance {
+			return
+		}
+	}
+}
+
+// getBoneTip returns the world position of the end of the bone
+func (p *EntityPlayer) getBoneTip(index int) *Point {
+	ref := p.currentKey.BoneRefs[index]
+	bone := p.unmappedInterpolatedKeys[ref.Timeline].object
+	length := p.animation.Timelines[ref.Timeline].objectInfo.Width
+	tip := MakePoint(length*bone.Scale.X(), 0)
+	tip.Rotate(bone.Angle)
+	tip.Add(bone.Position)
+	return tip
+}
+
+// rotateBone rotates the bone by the given world angle and updates all its children
+func (p *EntityPlayer) rotateBone(index int, angle float64) {
+	ref := p.currentKey.BoneRefs[index]
+	parent := p.getParentObject(ref)
+	local := p.interpolatedKeys[ref.Timeline].object
+	local.Angle += angle * signum(parent.Scale.X()) * signum(parent.Scale.Y())
+	p.unmapBonesFrom(index)
+}
+
+// unmapBonesFrom recomputes the world coordinates of the bones, starting from the given bone ref and following
+// the order of the key. Since parents come before their children, all the children of that bone are updated as well.
+func (p *EntityPlayer) unmapBonesFrom(index int) {
+	order := p.currentKey.boneOrder
+	for start := range order {
+		if order[start] != index {
+			continue
+		}
+		for _, i := range order[start:] {
+			p.unmapRef(p.currentKey.BoneRefs[i])
+		}
+		return
+	}
+}
+
+func distance(a *Point, b *Point) float64 {
+	return math.Hypot(b.X()-a.X(), b.Y()-a.Y())
+}
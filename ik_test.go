@@ -0,0 +1,43 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAddIKChainErrors(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	for _, chain := range []*IKChain{
+		nil,
+		MakeIKChain("empty", IKCCD),
+		MakeIKChain("one", IKTwoBone, "upper"),
+		MakeIKChain("three", IKTwoBone, "root", "upper", "lower"),
+	} {
+		if err := p.AddIKChain(chain); err == nil {
+			t.Errorf("expected an error adding %+v", chain)
+		}
+	}
+	if len(p.ikChains) != 0 {
+		t.Errorf("invalid chains must not be added, got %d", len(p.ikChains))
+	}
+}
+
+func TestTwoBonesIK(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.Update(0)
+	// Reachable: the bones are 60 and 50 long
+	upper := p.getBoneByName("upper")
+	x, y := upper.Position.X()+30, upper.Position.Y()+70
+
+	chain := MakeIKChain("arm", IKTwoBone, "upper", "lower").SetTarget(x, y)
+	if err := p.AddIKChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	p.Update(0)
+	lower := p.getBoneByName("lower")
+	tipX := lower.Position.X() + 50*lower.Scale.X()*math.Cos(lower.Angle)
+	tipY := lower.Position.Y() + 50*lower.Scale.X()*math.Sin(lower.Angle)
+	if math.Hypot(tipX-x, tipY-y) > chain.Tolerance {
+		t.Errorf("expected the end of the chain at (%g, %g), got (%g, %g)", x, y, tipX, tipY)
+	}
+}
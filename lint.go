@@ -0,0 +1,372 @@
+package spriter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type LintSeverity int
+
+const (
+	LintInfo LintSeverity = iota
+	LintWarning
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintInfo:
+		return "info"
+	case LintWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+func ParseLintSeverity(name string) (LintSeverity, error) {
+	switch strings.ToLower(name) {
+	case "info":
+		return LintInfo, nil
+	case "warning":
+		return LintWarning, nil
+	case "error":
+		return LintError, nil
+	}
+	return LintError, fmt.Errorf("unknown severity '%s'", name)
+}
+
+// LintIssue is a problem found in a model
+type LintIssue struct {
+	Rule     string
+	Severity LintSeverity
+	// Where the problem is, e.g. "entity 'hero' > animation 'run'"
+	Location string
+	Message  string
+}
+
+func (i *LintIssue) String() string {
+	if i.Location == "" {
+		return fmt.Sprintf("%s [%s] %s", i.Severity, i.Rule, i.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s: %s", i.Severity, i.Rule, i.Location, i.Message)
+}
+
+type LintRule struct {
+	Id          string
+	Severity    LintSeverity
+	Description string
+	check       func(model *Model, report func(location string, message string))
+}
+
+// LintOptions configures which rules are run and with which severity
+type LintOptions struct {
+	Disabled   map[string]bool
+	Severities map[string]LintSeverity
+}
+
+func MakeLintOptions() *LintOptions {
+	return &LintOptions{
+		Disabled:   make(map[string]bool),
+		Severities: make(map[string]LintSeverity),
+	}
+}
+
+// LintRules are all the available rules, in the order they are run
+var LintRules = []*LintRule{
+	{"invalid-ref", LintError, "mainline refs pointing to timelines, keys or parents which don't exist", lintInvalidRefs},
+	{"missing-file", LintError, "timeline keys using a file which is not in the folders", lintMissingFiles},
+	{"missing-image", LintWarning, "files whose image is not found next to the SCML", lintMissingImages},
+	{"unused-file", LintWarning, "files never used by any key or character map", lintUnusedFiles},
+	{"unreferenced-timeline", LintWarning, "timelines never referenced by any mainline key", lintUnreferencedTimelines},
+	{"zero-length-animation", LintError, "animations with a length of zero", lintZeroLengthAnimations},
+	{"empty-mainline", LintError, "animations without mainline keys", lintEmptyMainlines},
+	{"charmap-missing-file", LintError, "character maps using files which are not in the folders", lintCharacterMapFiles},
+	{"duplicate-name", LintError, "entities, animations, timelines, character maps or files with the same name", lintDuplicateNames},
+	{"pivot-out-of-range", LintWarning, "pivots of files and keys outside [0,1]", lintPivots},
+	{"unknown-curve", LintWarning, "keys using a curve_type which is neither a SCML curve nor a registered easing", lintUnknownCurves},
+}
+
+func GetLintRule(id string) *LintRule {
+	for _, rule := range LintRules {
+		if rule.Id == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Lint checks the model for problems. Issues are sorted by severity (errors first), then by rule.
+func Lint(model *Model, options *LintOptions) []LintIssue {
+	if options == nil {
+		options = MakeLintOptions()
+	}
+	issues := make([]LintIssue, 0)
+	for _, rule := range LintRules {
+		if options.Disabled[rule.Id] {
+			continue
+		}
+		severity := rule.Severity
+		if s, ok := options.Severities[rule.Id]; ok {
+			severity = s
+		}
+		rule.check(model, func(location string, message string) {
+			issues = append(issues, LintIssue{Rule: rule.Id, Severity: severity, Location: location, Message: message})
+		})
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Severity > issues[j].Severity
+	})
+	return issues
+}
+
+func entityLocation(e *Entity) string {
+	return fmt.Sprintf("entity '%s'", e.Name)
+}
+
+func animationLocation(e *Entity, a *Animation) string {
+	return fmt.Sprintf("entity '%s' > animation '%s'", e.Name, a.Name)
+}
+
+func describeFileIndex(fileIndex int) string {
+	return fmt.Sprintf("folder %d, file %d", fileIndex>>NumBitsPerFolder, fileIndex&(1<<NumBitsPerFolder-1))
+}
+
+func lintInvalidRefs(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			for _, key := range a.Mainline.Keys {
+				check := func(ref *ObjectRef, kind string) {
+					if ref.Timeline < 0 || ref.Timeline >= len(a.Timelines) {
+						report(animationLocation(e, a), fmt.Sprintf("mainline key %d: %s %d uses timeline %d, which doesn't exist", key.Id, kind, ref.Id, ref.Timeline))
+						return
+					}
+					if ref.Key < 0 || ref.Key >= len(a.Timelines[ref.Timeline].Keys) {
+						report(animationLocation(e, a), fmt.Sprintf("mainline key %d: %s %d uses key %d of timeline '%s', which doesn't exist", key.Id, kind, ref.Id, ref.Key, a.Timelines[ref.Timeline].Name))
+					}
+					if ref.Parent != nil && *ref.Parent >= 0 && ref.ParentRef == nil {
+						report(animationLocation(e, a), fmt.Sprintf("mainline key %d: %s %d has parent %d, which doesn't exist", key.Id, kind, ref.Id, *ref.Parent))
+					}
+				}
+				for _, ref := range key.BoneRefs {
+					check(ref, "bone_ref")
+				}
+				for _, ref := range key.ObjectRefs {
+					check(ref, "object_ref")
+				}
+			}
+		}
+	}
+}
+
+func lintMissingFiles(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			for _, t := range a.Timelines {
+				if t.ObjectType != TypeSprite {
+					continue
+				}
+				for _, key := range t.Keys {
+					if key.object.fileIndex >= 0 && model.GetFile(key.object.fileIndex) == nil {
+						report(animationLocation(e, a), fmt.Sprintf("key %d of timeline '%s' uses %s, which doesn't exist", key.Id, t.Name, describeFileIndex(key.object.fileIndex)))
+					}
+				}
+			}
+		}
+	}
+}
+
+func lintMissingImages(model *Model, report func(string, string)) {
+	for _, folder := range model.Folders {
+		for _, file := range folder.Files {
+			if _, err := os.Stat(filepath.Join(model.GetDir(), file.Name)); err != nil {
+				report(fmt.Sprintf("folder '%s'", folder.Name), fmt.Sprintf("image '%s' not found", file.Name))
+			}
+		}
+	}
+}
+
+func lintUnusedFiles(model *Model, report func(string, string)) {
+	used := make(map[int]bool)
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			for _, t := range a.Timelines {
+				if t.ObjectType != TypeSprite {
+					continue
+				}
+				for _, key := range t.Keys {
+					used[key.object.fileIndex] = true
+				}
+			}
+		}
+		for _, m := range e.CharacterMaps {
+			for _, target := range m.FilesMapping {
+				used[target] = true
+			}
+		}
+	}
+	// Files are indexed by their position, like the loader does
+	for i, folder := range model.Folders {
+		for j, file := range folder.Files {
+			if !used[FolderAndFileToFileIndex(i, j)] {
+				report(fmt.Sprintf("folder '%s'", folder.Name), fmt.Sprintf("file '%s' is never used", file.Name))
+			}
+		}
+	}
+}
+
+func lintUnreferencedTimelines(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			referenced := make(map[int]bool)
+			for _, key := range a.Mainline.Keys {
+				for _, ref := range key.BoneRefs {
+					referenced[ref.Timeline] = true
+				}
+				for _, ref := range key.ObjectRefs {
+					referenced[ref.Timeline] = true
+				}
+			}
+			for i, t := range a.Timelines {
+				if !referenced[i] {
+					report(animationLocation(e, a), fmt.Sprintf("timeline '%s' is never referenced by the mainline", t.Name))
+				}
+			}
+		}
+	}
+}
+
+func lintZeroLengthAnimations(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			if a.Length <= 0 {
+				report(animationLocation(e, a), fmt.Sprintf("length is %d", a.Length))
+			}
+		}
+	}
+}
+
+func lintEmptyMainlines(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			if len(a.Mainline.Keys) == 0 {
+				report(animationLocation(e, a), "the mainline has no keys")
+			}
+		}
+	}
+}
+
+func lintCharacterMapFiles(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, m := range e.CharacterMaps {
+			location := fmt.Sprintf("%s > character map '%s'", entityLocation(e), m.Name)
+			for _, mapping := range m.Maps {
+				source := FolderAndFileToFileIndex(mapping.Folder, mapping.File)
+				if model.GetFile(source) == nil {
+					report(location, fmt.Sprintf("maps %s, which doesn't exist", describeFileIndex(source)))
+				}
+				if mapping.TargetFolder != nil && mapping.TargetFile != nil {
+					target := FolderAndFileToFileIndex(*mapping.TargetFolder, *mapping.TargetFile)
+					if model.GetFile(target) == nil {
+						report(location, fmt.Sprintf("maps to %s, which doesn't exist", describeFileIndex(target)))
+					}
+				}
+			}
+		}
+	}
+}
+
+func lintDuplicateNames(model *Model, report func(string, string)) {
+	checkNames := func(location string, kind string, names []string) {
+		seen := make(map[string]bool)
+		for _, name := range names {
+			if seen[name] {
+				report(location, fmt.Sprintf("more than one %s named '%s'", kind, name))
+			}
+			seen[name] = true
+		}
+	}
+
+	entities := make([]string, 0)
+	for _, e := range model.Entities {
+		entities = append(entities, e.Name)
+		animations := make([]string, 0)
+		for _, a := range e.Animations {
+			animations = append(animations, a.Name)
+			timelines := make([]string, 0)
+			for _, t := range a.Timelines {
+				timelines = append(timelines, t.Name)
+			}
+			checkNames(animationLocation(e, a), "timeline", timelines)
+		}
+		checkNames(entityLocation(e), "animation", animations)
+		maps := make([]string, 0)
+		for _, m := range e.CharacterMaps {
+			maps = append(maps, m.Name)
+		}
+		checkNames(entityLocation(e), "character map", maps)
+	}
+	checkNames("", "entity", entities)
+	for _, folder := range model.Folders {
+		files := make([]string, 0)
+		for _, file := range folder.Files {
+			files = append(files, file.Name)
+		}
+		checkNames(fmt.Sprintf("folder '%s'", folder.Name), "file", files)
+	}
+}
+
+func lintPivots(model *Model, report func(string, string)) {
+	outside := func(value float64) bool {
+		return value < 0 || value > 1
+	}
+	for _, folder := range model.Folders {
+		for _, file := range folder.Files {
+			if outside(file.PivotX) || outside(file.PivotY) {
+				report(fmt.Sprintf("folder '%s'", folder.Name), fmt.Sprintf("file '%s' has pivot %g,%g", file.Name, file.PivotX, file.PivotY))
+			}
+		}
+	}
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			for _, t := range a.Timelines {
+				if t.ObjectType != TypeSprite {
+					continue
+				}
+				// Keys without their own pivot use the one of the file, already checked
+				for _, key := range t.Keys {
+					if key.object.XMLPivotX == nil && key.object.XMLPivotY == nil {
+						continue
+					}
+					pivot := key.object.Pivot
+					if outside(pivot.X()) || outside(pivot.Y()) {
+						report(animationLocation(e, a), fmt.Sprintf("key %d of timeline '%s' has pivot %g,%g", key.Id, t.Name, pivot.X(), pivot.Y()))
+					}
+				}
+			}
+		}
+	}
+}
+
+// lintUnknownCurves reports the curves which play as linear because their easing is not registered
+func lintUnknownCurves(model *Model, report func(string, string)) {
+	for _, e := range model.Entities {
+		for _, a := range e.Animations {
+			for _, key := range a.Mainline.Keys {
+				if key.CurveType != nil && GetEasing(*key.CurveType) == nil {
+					report(animationLocation(e, a), fmt.Sprintf("mainline key %d uses the unknown curve '%s'", key.Id, *key.CurveType))
+				}
+			}
+			for _, t := range a.Timelines {
+				for _, key := range t.Keys {
+					if key.CurveType != "" && GetEasing(key.CurveType) == nil {
+						report(animationLocation(e, a), fmt.Sprintf("key %d of timeline '%s' uses the unknown curve '%s'", key.Id, t.Name, key.CurveType))
+					}
+				}
+			}
+		}
+	}
+}
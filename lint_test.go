@@ -0,0 +1,166 @@
+package spriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A model without issues: file 0 is drawn, file 1 is used by the character map
+const lintTestSCML = `<spriter_data scml_version="1.0">
+    <folder id="0" name="parts">
+        <file id="0" name="parts/body.png" width="10" height="10" pivot_x="0.5" pivot_y="0.5"/>
+        <file id="1" name="parts/arm.png" width="10" height="10" pivot_x="0" pivot_y="0.5"/>
+    </folder>
+    <entity id="0" name="Hero">
+        <character_map id="0" name="alt">
+            <map folder="0" file="0" target_folder="0" target_file="1"/>
+        </character_map>
+        <animation id="0" name="idle" length="1000">
+            <mainline>
+                <key id="0"><object_ref id="0" timeline="0" key="0" z_index="0"/></key>
+            </mainline>
+            <timeline id="0" name="body">
+                <key id="0"><object folder="0" file="0"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+// lintTestFixtures breaks the model for each rule
+var lintTestFixtures = map[string][]string{
+	"invalid-ref":           {`key="0" z_index`, `key="3" z_index`},
+	"missing-file":          {`<object folder="0" file="0"/>`, `<object folder="0" file="5"/>`},
+	"missing-image":         {`parts/arm.png`, `parts/missing.png`},
+	"unused-file":           {`target_file="1"`, `target_file="0"`},
+	"unreferenced-timeline": {`</animation>`, `<timeline id="1" name="extra"><key id="0"><object folder="0" file="0"/></key></timeline></animation>`},
+	"zero-length-animation": {`length="1000"`, `length="0"`},
+	"empty-mainline":        {`<key id="0"><object_ref id="0" timeline="0" key="0" z_index="0"/></key>`, ``},
+	"charmap-missing-file":  {`target_file="1"`, `target_file="7"`},
+	"duplicate-name":        {`</entity>`, `<animation id="1" name="idle" length="10"><mainline><key id="0"/></mainline></animation></entity>`},
+	"pivot-out-of-range":    {`pivot_x="0.5"`, `pivot_x="1.5"`},
+	"unknown-curve":         {`<key id="0"><object_ref`, `<key id="0" curve_type="wobble"><object_ref`},
+}
+
+// writeLintTestModel writes the SCML and the images of the files in the base model
+func writeLintTestModel(t *testing.T, scml string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "parts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"body.png", "arm.png"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "parts", name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fileName := filepath.Join(dir, "model.scml")
+	if err := ioutil.WriteFile(fileName, []byte(scml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func loadLintTestModel(t *testing.T, rule string) *Model {
+	t.Helper()
+	scml := lintTestSCML
+	if fixture, ok := lintTestFixtures[rule]; ok {
+		if !strings.Contains(scml, fixture[0]) {
+			t.Fatalf("the fixture of %s doesn't apply", rule)
+		}
+		scml = strings.Replace(scml, fixture[0], fixture[1], 1)
+	}
+	model, err := LoadModel(writeLintTestModel(t, scml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func findLintIssue(issues []LintIssue, rule string) *LintIssue {
+	for i := range issues {
+		if issues[i].Rule == rule {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestLintRules(t *testing.T) {
+	if issues := Lint(loadLintTestModel(t, ""), nil); len(issues) != 0 {
+		t.Fatalf("expected no issues in the base model, got %v", issues)
+	}
+	for _, rule := range LintRules {
+		t.Run(rule.Id, func(t *testing.T) {
+			if _, ok := lintTestFixtures[rule.Id]; !ok {
+				t.Fatalf("no fixture for %s", rule.Id)
+			}
+			issue := findLintIssue(Lint(loadLintTestModel(t, rule.Id), nil), rule.Id)
+			if issue == nil {
+				t.Fatalf("%s not reported", rule.Id)
+			}
+			if issue.Severity != rule.Severity || issue.Message == "" {
+				t.Errorf("expected a %s, got %s", rule.Severity, issue.String())
+			}
+		})
+	}
+}
+
+func TestLintOptions(t *testing.T) {
+	// An error and a warning: the file 0 is not used anymore
+	model := loadLintTestModel(t, "missing-file")
+
+	issues := Lint(model, nil)
+	if len(issues) != 2 || issues[0].Rule != "missing-file" || issues[1].Rule != "unused-file" {
+		t.Fatalf("expected the error then the warning, got %v", issues)
+	}
+
+	options := MakeLintOptions()
+	options.Severities["unused-file"] = LintError
+	options.Severities["missing-file"] = LintInfo
+	issues = Lint(model, options)
+	if len(issues) != 2 || issues[0].Rule != "unused-file" || issues[0].Severity != LintError ||
+		issues[1].Rule != "missing-file" || issues[1].Severity != LintInfo {
+		t.Errorf("expected the overridden severities, errors first, got %v", issues)
+	}
+
+	options = MakeLintOptions()
+	options.Disabled["missing-file"] = true
+	issues = Lint(model, options)
+	if len(issues) != 1 || issues[0].Rule != "unused-file" {
+		t.Errorf("expected only the enabled rule, got %v", issues)
+	}
+}
+
+func TestParseLintSeverity(t *testing.T) {
+	for _, severity := range []LintSeverity{LintInfo, LintWarning, LintError} {
+		if parsed, err := ParseLintSeverity(strings.ToUpper(severity.String())); err != nil || parsed != severity {
+			t.Errorf("expected %s, got %s (%v)", severity, parsed, err)
+		}
+	}
+	if _, err := ParseLintSeverity("fatal"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestLintUnknownCurves(t *testing.T) {
+	name := makeTestEasingName("lint-wobble")
+	scml := strings.Replace(lintTestSCML, `<key id="0"><object folder`, `<key id="0" curve_type="`+name+`"><object folder`, 1)
+	model, err := LoadModel(writeLintTestModel(t, scml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := Lint(model, nil)
+	if len(issues) != 1 || issues[0].Rule != "unknown-curve" || !strings.Contains(issues[0].Message, "timeline 'body'") {
+		t.Fatalf("expected the unknown curve of the timeline key, got %v", issues)
+	}
+
+	if err := RegisterEasing(name, EasingFunc(BounceEasing)); err != nil {
+		t.Fatal(err)
+	}
+	if issues := Lint(model, nil); len(issues) != 0 {
+		t.Errorf("registered easings are known, got %v", issues)
+	}
+}
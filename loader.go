@@ -1,34 +1,34 @@
 package spriter
 
 import (
-	"os"
+	"path/filepath"
 	"fmt"
-	"io/ioutil"
 	"math"
-	"encoding/xml"
 )
 
 func NewSpriterModelFromFile(fileName string) *Model {
 	fmt.Println("=== Reading SCML ===")
 
-	// Open the xml file
-	xmlFile, err := os.Open(fileName)
-	defer xmlFile.Close()
+	// An invalid SCML gives what has been parsed before the error
+	model, err := readModelFile(fileName)
 	if err != nil {
 		fmt.Println(err)
 	}
+	if model == nil {
+		model = &Model{dir: filepath.Dir(fileName)}
+		initializeData(model)
+	}
+	return model
+}
 
-	model := &Model{}
-
-	// Read the data into the model
-	byteData, _ := ioutil.ReadAll(xmlFile)
-	err = xml.Unmarshal(byteData, &model)
+// LoadModel reads a SCML, SCON or binary file, depending on its extension, and prepares the model for playing.
+// Unlike NewSpriterModelFromFile, it doesn't print anything and returns the errors, with a nil model.
+func LoadModel(fileName string) (*Model, error) {
+	model, err := readModelFile(fileName)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-
-	initializeData(model)
-	return model
+	return model, nil
 }
 
 func initializeData(data *Model) {
@@ -45,6 +45,7 @@ func initializeData(data *Model) {
 
 		// Entities
 		entity := data.Entities[i]
+		entity.model = data
 		for j := range entity.CharacterMaps {
 			m := entity.CharacterMaps[j]
 			m.FilesMapping = make(map[int]int)
@@ -64,6 +65,9 @@ func initializeData(data *Model) {
 		// Animations
 		for j := range entity.Animations {
 			a := entity.Animations[j]
+			if a.Mainline == nil {
+				a.Mainline = &Mainline{}
+			}
 			a.initialize()
 			a.Looping = optionalBool(a.XMLLooping, true)
 
@@ -75,13 +79,12 @@ func initializeData(data *Model) {
 			// Mainline
 			for k := range a.Mainline.Keys {
 				key := a.Mainline.Keys[k]
-				curve := MakeCurve()
-				key.curve = curve
-				if key.CurveType == nil {
-					key.curve.curveType = TypeLinear
-				} else {
-					key.curve.curveType = getCurveTypeFromName(*key.CurveType)
+				// Unknown curves play as linear and keep their name, the lint rule unknown-curve reports them
+				key.curve = MakeCurve()
+				if key.CurveType != nil {
+					key.curve = MakeCurveWithName(*key.CurveType)
 				}
+				key.curve.SetConstraints(key.C1, key.C2, key.C3, key.C4)
 				for z := range key.BoneRefs {
 					ref := key.BoneRefs[z]
 					if ref.Parent != nil && *ref.Parent >= 0 && *ref.Parent < len(key.BoneRefs) {
@@ -94,6 +97,7 @@ func initializeData(data *Model) {
 						ref.ParentRef = key.BoneRefs[*ref.Parent]
 					}
 				}
+				key.initializeBoneOrder()
 			}
 			// Timelines
 			for k := range a.Timelines {
@@ -110,9 +114,7 @@ func initializeData(data *Model) {
 
 				for z := range timeline.Keys {
 					key := timeline.Keys[z]
-					curve := MakeCurveWithType(getCurveTypeFromName(key.CurveType))
-					curve.constraints = [4]float64{key.C1, key.C2, key.C3, key.C4}
-					key.Curve = curve
+					key.Curve = MakeCurveWithName(key.CurveType).SetConstraints(key.C1, key.C2, key.C3, key.C4)
 					key.Spin = optionalInt(key.XMLSpin, 1)
 
 					if key.XMLDataBone != nil {
@@ -129,15 +131,20 @@ func initializeData(data *Model) {
 						key.object = key.XMLDataObject
 						key.object.objectType = timeline.ObjectType
 						o := key.object
-						o.fileIndex = FolderAndFileToFileIndex(o.Folder, o.File)
-						f := data.Files[o.fileIndex]
-						if timeline.objectInfo != nil {
-							timeline.objectInfo.Width = float64(f.Width)
-							timeline.objectInfo.Height = float64(f.Height)
+						pivotX, pivotY := 0.0, 0.0
+						o.fileIndex = -1
+						if timeline.ObjectType == TypeSprite {
+							// Boxes and points don't have an image, their size comes from obj_info
+							o.fileIndex = FolderAndFileToFileIndex(o.Folder, o.File)
+							if f := data.Files[o.fileIndex]; f != nil {
+								timeline.objectInfo.Width = float64(f.Width)
+								timeline.objectInfo.Height = float64(f.Height)
+								pivotX, pivotY = f.PivotX, f.PivotY
+							}
 						}
 						key.object.Pivot = MakePoint(
-							optionalFloat(key.object.XMLPivotX, f.PivotX),
-							optionalFloat(key.object.XMLPivotY, f.PivotY),
+							optionalFloat(key.object.XMLPivotX, pivotX),
+							optionalFloat(key.object.XMLPivotY, pivotY),
 						)
 						key.XMLDataObject = nil
 					}
@@ -146,6 +153,7 @@ func initializeData(data *Model) {
 						optionalFloat(key.object.XMLScaleX, 1),
 						optionalFloat(key.object.XMLScaleY, 1),
 					)
+					key.object.Alpha = optionalFloat(key.object.XMLAlpha, 1)
 					// Convert degrees to radians
 					key.object.Angle = (math.Pi * key.object.Angle) / 180.0
 				}
@@ -0,0 +1,32 @@
+package spriter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadInvalidSCML(t *testing.T) {
+	// Truncated after the first entity
+	end := strings.Index(testHeroSCML, "</entity>") + len("</entity>")
+	fileName := filepath.Join(t.TempDir(), "hero.scml")
+	if err := ioutil.WriteFile(fileName, []byte(testHeroSCML[:end]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if model, err := LoadModel(fileName); err == nil || model != nil {
+		t.Errorf("LoadModel must fail without a model, got %v and %v", model, err)
+	}
+
+	model := NewSpriterModelFromFile(fileName)
+	if len(model.Entities) != 1 || model.GetEntityByName("Hero") == nil {
+		t.Fatalf("expected the entity parsed before the error, got %d entities", len(model.Entities))
+	}
+	if model.GetDir() != filepath.Dir(fileName) {
+		t.Errorf("expected the dir of the file, got %s", model.GetDir())
+	}
+	if len(model.Files) != 3 {
+		t.Errorf("expected the files of the partial model, got %d", len(model.Files))
+	}
+}
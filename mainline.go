@@ -1,6 +1,9 @@
 package spriter
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 type Mainline struct {
 	Keys []*MainlineKey `xml:"key"`
@@ -34,7 +37,13 @@ type MainlineKey struct {
 	BoneRefs   []*ObjectRef `xml:"bone_ref"`
 	ObjectRefs []*ObjectRef `xml:"object_ref"`
 	CurveType  *string      `xml:"curve_type,attr"`
+	C1         float64      `xml:"c1,attr"`
+	C2         float64      `xml:"c2,attr"`
+	C3         float64      `xml:"c3,attr"`
+	C4         float64      `xml:"c4,attr"`
 	curve      *Curve
+	// Indices of the bone refs, parents before their children
+	boneOrder []int
 }
 
 func (k *MainlineKey) String() string {
@@ -49,6 +58,30 @@ func (k *MainlineKey) String() string {
 	return toReturn
 }
 
+// initializeBoneOrder sorts the bone refs so that parents come before their children. Spriter writes them
+// in this order, the file order is kept when possible. Refs in a cycle of parents are appended in the file order.
+func (k *MainlineKey) initializeBoneOrder() {
+	k.boneOrder = make([]int, 0, len(k.BoneRefs))
+	added := make(map[*ObjectRef]bool, len(k.BoneRefs))
+	for len(k.boneOrder) < len(k.BoneRefs) {
+		progress := false
+		for i, ref := range k.BoneRefs {
+			if !added[ref] && (ref.ParentRef == nil || added[ref.ParentRef]) {
+				k.boneOrder = append(k.boneOrder, i)
+				added[ref] = true
+				progress = true
+			}
+		}
+		if !progress {
+			for i, ref := range k.BoneRefs {
+				if !added[ref] {
+					k.boneOrder = append(k.boneOrder, i)
+				}
+			}
+		}
+	}
+}
+
 func (k *MainlineKey) Curve() *Curve {
 	if k.curve == nil {
 		k.curve = MakeCurve()
@@ -66,5 +99,14 @@ type ObjectRef struct {
 }
 
 func (r *ObjectRef) String() string {
-	return fmt.Sprintf("ObjectRef [id:%d, key:%d, parent:%d, timeline:%d, zIndex:%s]", r.Id, r.Key, r.Parent, r.Timeline, r.ZIndex)
+	return fmt.Sprintf("ObjectRef [id:%d, key:%d, parent:%d, timeline:%d, zIndex:%s]", r.Id, r.Key, optionalInt(r.Parent, -1), r.Timeline, r.ZIndex)
+}
+
+// zIndex returns the drawing order of the object. Refs without a valid z_index are drawn in the order they appear.
+func (r *ObjectRef) zIndex() int {
+	z, err := strconv.Atoi(r.ZIndex)
+	if err != nil {
+		return r.Id
+	}
+	return z
 }
@@ -16,91 +16,73 @@ func signum(f float64) float64 {
 	return ternary(f == 0.0 || math.IsNaN(f), f, math.Copysign(1.0, f))
 }
 
+// angleDifference returns the shortest rotation going from b to a, in [-Pi, Pi]
 func angleDifference(a float64, b float64) float64 {
-	return math.Min((2*math.Pi)-math.Abs(a-b), math.Abs(a-b))
+	return math.Remainder(a-b, 2*math.Pi)
+}
+
+// spinDifference returns the rotation going from a to b in the direction given by spin:
+// counterclockwise (positive) for spin 1, clockwise (negative) for spin -1, none for spin 0.
+// Full turns are ignored: equal angles give no rotation.
+func spinDifference(a float64, b float64, spin int) float64 {
+	delta := math.Mod(b-a, 2*math.Pi)
+	switch {
+	case spin > 0 && delta < 0:
+		delta += 2 * math.Pi
+	case spin < 0 && delta > 0:
+		delta -= 2 * math.Pi
+	case spin == 0:
+		delta = 0
+	}
+	return delta
 }
 
-// From https://github.com/Trixt0r/spriter
-func solveCubic(a float64, b float64, c float64, d float64) float64 {
-	if a == 0 {
-		return solveQuadratic(b, c, d)
+// bezierTolerance is the precision of solveBezier
+const bezierTolerance = 1e-12
+
+// solveBezier returns the parameter s, in [0, 1], where the x of a bezier going from (0, 0) to (1, 1), with
+// control points x1 and x2, equals x. x is clamped to [0, 1] (NaN counts as 0), so a solution always exists.
+// The result either satisfies |x(s) - x| <= bezierTolerance, or is within bezierTolerance of an exact solution.
+// When the curve goes back and forth (control points outside [0, 1]) any of the solutions can be returned.
+// Newton's method is used while it stays inside the interval known to contain a solution, bisection otherwise:
+// it converges in a few iterations on usual curves and in at most ~40 on the worst ones.
+func solveBezier(x1 float64, x2 float64, x float64) float64 {
+	if math.IsNaN(x) {
+		x = 0
 	}
-	if d == 0 {
-		return 0
+	x = math.Max(0, math.Min(1, x))
+	// x(s) = a s^3 + b s^2 + c s
+	a := 3*(x1-x2) + 1
+	b := 3 * (x2 - 2*x1)
+	c := 3 * x1
+	f := func(s float64) float64 {
+		return ((a*s+b)*s+c)*s - x
 	}
 
-	b /= a
-	c /= a
-	d /= a
-	squaredB := b * b
-	q := (3.0*c - squaredB) / 9.0
-	r := (-27.0*d + b*(9.0*c-2.0*squaredB)) / 54.0
-	discriminant := (q * q * q) + (r * r)
-	term1 := b / 3.0
-
-	if discriminant > 0 {
-		sqrtDisc := math.Sqrt(discriminant)
-		s := r + sqrtDisc
-		s = ternary(s < 0, -math.Cbrt(-s), math.Cbrt(s))
-		t := r - sqrtDisc
-		t = ternary(t < 0, -math.Cbrt(-t), math.Cbrt(t))
-
-		result := -term1 + s + t
-		if result >= 0 && result <= 1 {
-			return result
-		}
-	} else if discriminant == 0 {
-		r13 := ternary(r < 0, -math.Cbrt(-r), math.Cbrt(r))
-
-		result := -term1 + 2.0*r13
-		if result >= 0 && result <= 1 {
-			return result
+	// f(low) <= 0 <= f(high) holds at each step
+	low, high := 0.0, 1.0
+	s := x
+	for i := 0; i < 100; i++ {
+		value := f(s)
+		if math.Abs(value) <= bezierTolerance {
+			return s
 		}
-		result = -(r13 + term1)
-		if result >= 0 && result <= 1 {
-			return result
+		if value < 0 {
+			low = s
+		} else {
+			high = s
 		}
-	} else {
-		q = -q
-		dum1 := q * q * q
-		dum1 = math.Acos(r / math.Sqrt(dum1))
-		r13 := 2.0 * math.Sqrt(q)
-
-		result := -term1 + r13*math.Cos(dum1/3.0)
-		if result >= 0 && result <= 1 {
-			return result
-		}
-
-		result = -term1 + r13*math.Cos((dum1+2.0*math.Pi)/3.0)
-		if result >= 0 && result <= 1 {
-			return result
+		if high-low <= bezierTolerance {
+			return (low + high) / 2
 		}
-
-		result = -term1 + r13*math.Cos((dum1+4.0*math.Pi)/3.0)
-		if result >= 0 && result <= 1 {
-			return result
+		derivative := (3*a*s+2*b)*s + c
+		next := s - value/derivative
+		if derivative == 0 || math.IsNaN(next) || next <= low || next >= high {
+			next = (low + high) / 2
 		}
+		s = next
 	}
-
-	return -1
-}
-
-func solveQuadratic(a float64, b float64, c float64) float64 {
-	squaredB := b * b
-	twoA := 2 * a
-	fourAC := 4 * a * c
-	squareRoot := math.Sqrt(squaredB - fourAC)
-	result := (-b + squareRoot) / twoA
-	if result >= 0 && result <= 1 {
-		return result
-	}
-
-	result = (-b - squareRoot) / twoA
-	if result >= 0 && result <= 1 {
-		return result
-	}
-
-	return -1
+	return s
 }
 
 func Linear(a float64, b float64, t float64) float64 {
@@ -126,6 +108,7 @@ func Quintic(a float64, b float64, c float64, d float64, e float64, f float64, t
 	return Linear(Quartic(a, b, c, d, e, t), Quartic(b, c, d, e, f, t), t)
 }
 
+// LinearAngle interpolates two angles, in radians, along the shortest path
 func LinearAngle(a float64, b float64, t float64) float64 {
 	return a + angleDifference(b, a)*t
 }
@@ -0,0 +1,79 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolveBezier(t *testing.T) {
+	tests := []struct {
+		x1, x2, x float64
+		expected  float64
+	}{
+		// A straight line: s = x
+		{1.0 / 3, 2.0 / 3, 0.25, 0.25},
+		{1.0 / 3, 2.0 / 3, 0.5, 0.5},
+		// Symmetric curves go through the middle
+		{0.25, 0.75, 0.5, 0.5},
+		{0, 1, 0.5, 0.5},
+		{1, 0, 0.5, 0.5},
+		// x is clamped
+		{0.25, 0.75, -1, 0},
+		{0.25, 0.75, 2, 1},
+		{0.25, 0.75, math.NaN(), 0},
+	}
+	for _, test := range tests {
+		if s := solveBezier(test.x1, test.x2, test.x); math.Abs(s-test.expected) > 1e-9 {
+			t.Errorf("solveBezier(%g, %g, %g): expected %g, got %g", test.x1, test.x2, test.x, test.expected, s)
+		}
+	}
+}
+
+func FuzzSolveBezier(f *testing.F) {
+	seeds := [][3]float64{
+		{0.25, 0.75, 0.5},
+		// Degenerate: flat at the ends, or control points on the ends
+		{0, 0, 0.3},
+		{1, 1, 0.7},
+		{0, 1, 0.001},
+		{1, 0, 0.999},
+		{0.5, 0.5, 0.5},
+		// Non-monotonic: the curve goes back and forth, there are several solutions
+		{-1, 2, 0.5},
+		{2, -1, 0.5},
+		{3, 3, 0.9},
+		{-2, -2, 0.1},
+		{10, -10, 0.5},
+		// x at and outside the ends
+		{0.25, 0.75, 0},
+		{0.25, 0.75, 1},
+		{0.25, 0.75, -0.5},
+		{0.25, 0.75, 1.5},
+	}
+	for _, seed := range seeds {
+		f.Add(seed[0], seed[1], seed[2])
+	}
+	f.Fuzz(func(t *testing.T, x1 float64, x2 float64, x float64) {
+		s := solveBezier(x1, x2, x)
+		if math.IsNaN(s) || s < 0 || s > 1 {
+			t.Fatalf("solveBezier(%g, %g, %g) = %g, outside [0, 1]", x1, x2, x, s)
+		}
+		// With huge control points the rounding errors are larger than the tolerance
+		if math.IsNaN(x) || math.Abs(x1) > 1e3 || math.Abs(x2) > 1e3 {
+			return
+		}
+		x = math.Max(0, math.Min(1, x))
+		a, b, c := 3*(x1-x2)+1, 3*(x2-2*x1), 3*x1
+		residual := math.Abs(((a*s+b)*s+c)*s - x)
+		if residual <= bezierTolerance {
+			return
+		}
+		// Otherwise the interval found by the bisection is smaller than the tolerance: there is a solution
+		// within bezierTolerance of s, and x(s) can't be further from x than the slope allows
+		slope := 3*math.Abs(a) + 2*math.Abs(b) + math.Abs(c)
+		rounding := 1e-15 * (math.Abs(a) + math.Abs(b) + math.Abs(c) + 1)
+		if residual > bezierTolerance*slope+rounding {
+			t.Fatalf("solveBezier(%g, %g, %g) = %g, x(s) is %g away from x", x1, x2, x, s, residual)
+		}
+	})
+}
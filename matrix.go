@@ -0,0 +1,67 @@
+package spriter
+
+import "fmt"
+
+// Matrix is a 2D affine transformation, stored as [a, b, c, d, tx, ty].
+// It maps (x, y) to (a*x + c*y + tx, b*x + d*y + ty).
+// The order of the values is the same used by SVG and by the HTML canvas.
+type Matrix [6]float64
+
+func MakeIdentityMatrix() *Matrix {
+	return &Matrix{1, 0, 0, 1, 0, 0}
+}
+
+// MakeTransformMatrix returns the matrix which scales, rotates (radians) and then translates
+func MakeTransformMatrix(x float64, y float64, angle float64, scaleX float64, scaleY float64) *Matrix {
+	m := MakeIdentityMatrix()
+	p := MakePoint(1, 0).Rotate(angle)
+	m[0], m[1] = p.X()*scaleX, p.Y()*scaleX
+	m[2], m[3] = -p.Y()*scaleY, p.X()*scaleY
+	m[4], m[5] = x, y
+	return m
+}
+
+func (m *Matrix) Apply(x float64, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+func (m *Matrix) ApplyToPoint(p *Point) *Point {
+	p[0], p[1] = m.Apply(p[0], p[1])
+	return p
+}
+
+// Multiply sets the matrix to m * other: other is applied first
+func (m *Matrix) Multiply(other *Matrix) *Matrix {
+	a := m[0]*other[0] + m[2]*other[1]
+	b := m[1]*other[0] + m[3]*other[1]
+	c := m[0]*other[2] + m[2]*other[3]
+	d := m[1]*other[2] + m[3]*other[3]
+	tx := m[0]*other[4] + m[2]*other[5] + m[4]
+	ty := m[1]*other[4] + m[3]*other[5] + m[5]
+	m[0], m[1], m[2], m[3], m[4], m[5] = a, b, c, d, tx, ty
+	return m
+}
+
+func (m *Matrix) Determinant() float64 {
+	return m[0]*m[3] - m[1]*m[2]
+}
+
+// Invert returns the inverse of the matrix, or nil if it can't be inverted
+func (m *Matrix) Invert() *Matrix {
+	det := m.Determinant()
+	if det == 0 {
+		return nil
+	}
+	return &Matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}
+}
+
+func (m *Matrix) String() string {
+	return fmt.Sprintf("[%f,%f,%f,%f,%f,%f]", m[0], m[1], m[2], m[3], m[4], m[5])
+}
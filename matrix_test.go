@@ -0,0 +1,61 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func checkMatrix(t *testing.T, name string, m *Matrix, expected Matrix) {
+	t.Helper()
+	for i := range expected {
+		if math.Abs(m[i]-expected[i]) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", name, expected.String(), m.String())
+			return
+		}
+	}
+}
+
+func TestMatrixMultiply(t *testing.T) {
+	// The scale and the rotation are applied before the translation
+	m := MakeTransformMatrix(10, 20, math.Pi/2, 2, 3)
+	x, y := m.Apply(1, 1)
+	if math.Abs(x-7) > 1e-9 || math.Abs(y-22) > 1e-9 {
+		t.Errorf("expected (7, 22), got (%g, %g)", x, y)
+	}
+
+	// other is applied first
+	translate := &Matrix{1, 0, 0, 1, 5, 0}
+	rotate := MakeTransformMatrix(0, 0, math.Pi/2, 1, 1)
+	checkMatrix(t, "rotate * translate", rotate.Multiply(translate), Matrix{0, 1, -1, 0, 0, 5})
+	rotate = MakeTransformMatrix(0, 0, math.Pi/2, 1, 1)
+	checkMatrix(t, "translate * rotate", translate.Multiply(rotate), Matrix{0, 1, -1, 0, 5, 0})
+}
+
+func TestMatrixInvert(t *testing.T) {
+	for _, m := range []*Matrix{
+		MakeIdentityMatrix(),
+		MakeTransformMatrix(10, -20, 0.3, 2, 0.5),
+		MakeTransformMatrix(-3, 7, -2, -1, 1),
+		MakeTransformMatrix(0, 0, math.Pi, 1, -4),
+		{1, 2, 3, 4, 5, 6},
+	} {
+		inverse := m.Invert()
+		if inverse == nil {
+			t.Fatalf("%v must be invertible", m.String())
+		}
+		product := *inverse
+		checkMatrix(t, "inverse * m", product.Multiply(m), *MakeIdentityMatrix())
+		product = *m
+		checkMatrix(t, "m * inverse", product.Multiply(inverse), *MakeIdentityMatrix())
+	}
+
+	for _, m := range []*Matrix{
+		MakeTransformMatrix(1, 2, 0.5, 0, 1),
+		MakeTransformMatrix(1, 2, 0.5, 1, 0),
+		{1, 2, 2, 4, 0, 0},
+	} {
+		if inverse := m.Invert(); inverse != nil {
+			t.Errorf("%v is singular, got the inverse %v", m.String(), inverse.String())
+		}
+	}
+}
@@ -8,6 +8,7 @@ type Model struct {
 	Folders          []*Folder `xml:"folder"`
 	nameToEntity     map[string]*Entity
 	Files            map[int]*File
+	dir              string
 }
 
 type Folder struct {
@@ -44,3 +45,8 @@ func (m *Model) GetFile(fileIndex int) *File {
 		return m.Files[fileIndex]
 	}
 }
+
+// GetDir returns the folder of the file the model has been loaded from. The names of the files are relative to it.
+func (m *Model) GetDir() string {
+	return m.dir
+}
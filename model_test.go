@@ -0,0 +1,99 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+// loadTestModel reads a model from an inline SCML document
+func loadTestModel(t testing.TB, scml string) *Model {
+	t.Helper()
+	model, err := DecodeModel([]byte(scml), FormatSCML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+// A character with a bone hierarchy, sprites, a point, a character map, eased keys and two animations
+const testHeroSCML = `<spriter_data scml_version="1.0" generator="BrashMonkey Spriter" generator_version="r11">
+    <folder id="0" name="parts">
+        <file id="0" name="parts/body.png" width="32" height="64" pivot_x="0.5" pivot_y="0"/>
+        <file id="1" name="parts/arm.png" width="40" height="10" pivot_x="0" pivot_y="0.5"/>
+        <file id="2" name="parts/sword.png" width="50" height="8" pivot_x="0" pivot_y="0.5"/>
+    </folder>
+    <entity id="0" name="Hero">
+        <obj_info name="root" type="bone" w="50" h="10"/>
+        <obj_info name="upper" type="bone" w="60" h="10"/>
+        <obj_info name="lower" type="bone" w="50" h="10"/>
+        <character_map id="0" name="armed">
+            <map folder="0" file="1" target_folder="0" target_file="2"/>
+        </character_map>
+        <animation id="0" name="walk" length="1000" interval="100">
+            <mainline>
+                <key id="0">
+                    <bone_ref id="0" timeline="0" key="0"/>
+                    <bone_ref id="1" parent="0" timeline="1" key="0"/>
+                    <bone_ref id="2" parent="1" timeline="2" key="0"/>
+                    <object_ref id="0" parent="0" timeline="3" key="0" z_index="0"/>
+                    <object_ref id="1" parent="2" timeline="4" key="0" z_index="1"/>
+                    <object_ref id="2" parent="0" timeline="5" key="0" z_index="2"/>
+                </key>
+                <key id="1" time="500" curve_type="quadratic" c1="0.2">
+                    <bone_ref id="0" timeline="0" key="1"/>
+                    <bone_ref id="1" parent="0" timeline="1" key="1"/>
+                    <bone_ref id="2" parent="1" timeline="2" key="0"/>
+                    <object_ref id="0" parent="0" timeline="3" key="0" z_index="0"/>
+                    <object_ref id="1" parent="2" timeline="4" key="0" z_index="1"/>
+                    <object_ref id="2" parent="0" timeline="5" key="0" z_index="2"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="root" object_type="bone">
+                <key id="0"><bone x="0" y="0" angle="90"/></key>
+                <key id="1" time="500"><bone x="0" y="-100" angle="90"/></key>
+            </timeline>
+            <timeline id="1" name="upper" object_type="bone">
+                <key id="0"><bone x="40" y="0" angle="350"/></key>
+                <key id="1" time="500" spin="-1" curve_type="bounce"><bone x="40" y="0" angle="300"/></key>
+            </timeline>
+            <timeline id="2" name="lower" object_type="bone">
+                <key id="0"><bone x="60" y="0" angle="20"/></key>
+            </timeline>
+            <timeline id="3" name="body">
+                <key id="0"><object folder="0" file="0" x="0" y="0" angle="270"/></key>
+            </timeline>
+            <timeline id="4" name="arm">
+                <key id="0"><object folder="0" file="1" x="0" y="0" angle="0" a="0.8" pivot_x="0.1" pivot_y="0.4"/></key>
+            </timeline>
+            <timeline id="5" name="hand" object_type="point">
+                <key id="0"><object x="10" y="5" angle="0"/></key>
+            </timeline>
+        </animation>
+        <animation id="1" name="idle" length="600" looping="false">
+            <mainline>
+                <key id="0">
+                    <bone_ref id="0" timeline="0" key="0"/>
+                    <object_ref id="0" parent="0" timeline="1" key="0" z_index="0"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="root" object_type="bone">
+                <key id="0"><bone x="0" y="0" angle="90"/></key>
+            </timeline>
+            <timeline id="1" name="body">
+                <key id="0"><object folder="0" file="0" x="0" y="0" angle="270"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+// checkBone compares the world coordinates of a bone, the angle in degrees
+func checkBone(t *testing.T, p *EntityPlayer, name string, x float64, y float64, angle float64) {
+	t.Helper()
+	bone := p.getBoneByName(name)
+	const tolerance = 1e-9
+	angleDifference := math.Remainder(bone.Angle-angle*math.Pi/180, 2*math.Pi)
+	if math.Abs(bone.Position.X()-x) > tolerance || math.Abs(bone.Position.Y()-y) > tolerance || math.Abs(angleDifference) > tolerance {
+		t.Errorf("%s: expected (%g, %g) at %g°, got (%g, %g) at %g°",
+			name, x, y, angle, bone.Position.X(), bone.Position.Y(), bone.Angle*180/math.Pi)
+	}
+}
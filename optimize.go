@@ -0,0 +1,227 @@
+package spriter
+
+import (
+	"fmt"
+	"math"
+)
+
+// KeyReductionOptions are the maximum differences allowed between a removed key and the value
+// interpolated by its neighbours. Values are compared in the space of the parent of the object.
+type KeyReductionOptions struct {
+	Position float64
+	// In degrees
+	Angle float64
+	Scale float64
+	// Relative to the size of the image, like the pivots of the keys
+	Pivot float64
+	Alpha float64
+}
+
+func MakeKeyReductionOptions() *KeyReductionOptions {
+	return &KeyReductionOptions{
+		Position: 0.1,
+		Angle:    0.1,
+		Scale:    0.001,
+		Pivot:    0.001,
+		Alpha:    0.001,
+	}
+}
+
+// Number of points compared inside each interval between two original keys
+const keyReductionSamples = 8
+
+// AnimationKeyReduction is the result of the reduction of an animation
+type AnimationKeyReduction struct {
+	Entity     string
+	Animation  string
+	KeysBefore int
+	KeysAfter  int
+	// Why the animation has not been reduced, empty if it has
+	Skipped string
+}
+
+type KeyReductionReport struct {
+	Animations []AnimationKeyReduction
+	KeysBefore int
+	KeysAfter  int
+}
+
+// Savings returns the fraction of the timeline keys which have been removed
+func (r *KeyReductionReport) Savings() float64 {
+	if r.KeysBefore == 0 {
+		return 0
+	}
+	return float64(r.KeysBefore-r.KeysAfter) / float64(r.KeysBefore)
+}
+
+func (r *KeyReductionReport) String() string {
+	return fmt.Sprintf("%d timeline keys -> %d (%.1f%% removed)", r.KeysBefore, r.KeysAfter, r.Savings()*100)
+}
+
+// ReduceKeys removes the timeline keys which can be reproduced, within the tolerances, by interpolating
+// the keys around them. The mainline refs are rewritten to point to the remaining keys.
+// First and last keys are always kept, as are keys where the parent of the object changes.
+// Animations with non-linear mainline curves are skipped.
+func ReduceKeys(model *Model, options *KeyReductionOptions) *KeyReductionReport {
+	report := &KeyReductionReport{Animations: make([]AnimationKeyReduction, 0)}
+	for _, entity := range model.Entities {
+		for _, animation := range entity.Animations {
+			result := animation.reduceKeys(options)
+			result.Entity = entity.Name
+			report.Animations = append(report.Animations, result)
+			report.KeysBefore += result.KeysBefore
+			report.KeysAfter += result.KeysAfter
+		}
+	}
+	return report
+}
+
+func (a *Animation) reduceKeys(options *KeyReductionOptions) AnimationKeyReduction {
+	result := AnimationKeyReduction{Animation: a.Name}
+	for _, timeline := range a.Timelines {
+		result.KeysBefore += len(timeline.Keys)
+	}
+	result.KeysAfter = result.KeysBefore
+	for _, key := range a.Mainline.Keys {
+		if key.Curve().curveType != TypeLinear {
+			result.Skipped = "non-linear mainline curves"
+			return result
+		}
+	}
+
+	barriers := a.getParentChanges()
+	result.KeysAfter = 0
+	for i, timeline := range a.Timelines {
+		keep := make([]bool, len(timeline.Keys))
+		for k := range keep {
+			keep[k] = k == 0 || k == len(keep)-1
+		}
+		prev := 0
+		removed := make([]*TimelineKey, 0)
+		for k := 1; k < len(timeline.Keys)-1; k++ {
+			candidate := append(removed, timeline.Keys[k])
+			if a.canInterpolate(timeline.Keys[prev], timeline.Keys[k+1], candidate, barriers[i], options) {
+				removed = candidate
+				continue
+			}
+			keep[k] = true
+			prev = k
+			removed = removed[:0]
+		}
+		a.removeKeys(i, keep)
+		result.KeysAfter += len(timeline.Keys)
+	}
+	return result
+}
+
+// getParentChanges returns, for each timeline, the times of the mainline keys where its ref appears,
+// disappears or changes parent. Keys can't be interpolated across them.
+func (a *Animation) getParentChanges() []map[int]bool {
+	changes := make([]map[int]bool, len(a.Timelines))
+	for i := range changes {
+		changes[i] = make(map[int]bool)
+	}
+	parents := func(key *MainlineKey) map[int]int {
+		result := make(map[int]int)
+		for _, refs := range [][]*ObjectRef{key.BoneRefs, key.ObjectRefs} {
+			for _, ref := range refs {
+				result[ref.Timeline] = -1
+				if ref.ParentRef != nil {
+					result[ref.Timeline] = ref.ParentRef.Timeline
+				}
+			}
+		}
+		return result
+	}
+	for k := 1; k < len(a.Mainline.Keys); k++ {
+		before, after := parents(a.Mainline.Keys[k-1]), parents(a.Mainline.Keys[k])
+		for i := range a.Timelines {
+			parentBefore, okBefore := before[i]
+			parentAfter, okAfter := after[i]
+			if okBefore != okAfter || parentBefore != parentAfter {
+				changes[i][a.Mainline.Keys[k].Time] = true
+			}
+		}
+	}
+	return changes
+}
+
+// canInterpolate tells if the motion from prev to next, through the removed keys, is reproduced by interpolating
+// prev and next. Each interval between two original keys is sampled with its own curve and spin, so eased keys
+// are only removed if the interpolation of prev follows them.
+func (a *Animation) canInterpolate(prev *TimelineKey, next *TimelineKey, removed []*TimelineKey, barriers map[int]bool, options *KeyReductionOptions) bool {
+	span := next.Time - prev.Time
+	if span <= 0 {
+		return false
+	}
+	for time := range barriers {
+		if time > prev.Time && time <= next.Time {
+			return false
+		}
+	}
+	for _, key := range removed {
+		if key.object.fileIndex != prev.object.fileIndex || key.object.objectType != prev.object.objectType {
+			return false
+		}
+	}
+
+	original := MakeTimelineKeyObject()
+	target := MakeTimelineKeyObject()
+	keys := append(append([]*TimelineKey{prev}, removed...), next)
+	for k := 0; k < len(keys)-1; k++ {
+		from, to := keys[k], keys[k+1]
+		if to.Time <= from.Time {
+			return false
+		}
+		for i := 1; i <= keyReductionSamples; i++ {
+			t := float64(i) / keyReductionSamples
+			time := float64(from.Time) + t*float64(to.Time-from.Time)
+			a.interpolateObject(from.object, to.object, original, t, from.Curve, from.Spin)
+			a.interpolateObject(prev.object, next.object, target, (time-float64(prev.Time))/float64(span), prev.Curve, prev.Spin)
+			if !isWithinTolerances(original, target, options) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isWithinTolerances(a *TimelineKeyObject, b *TimelineKeyObject, options *KeyReductionOptions) bool {
+	switch {
+	case distance(a.Position, b.Position) > options.Position,
+		math.Abs(angleDifference(a.Angle, b.Angle))*180/math.Pi > options.Angle,
+		math.Abs(a.Scale.X()-b.Scale.X()) > options.Scale,
+		math.Abs(a.Scale.Y()-b.Scale.Y()) > options.Scale,
+		math.Abs(a.Pivot.X()-b.Pivot.X()) > options.Pivot,
+		math.Abs(a.Pivot.Y()-b.Pivot.Y()) > options.Pivot,
+		math.Abs(a.Alpha-b.Alpha) > options.Alpha:
+		return false
+	}
+	return true
+}
+
+// removeKeys keeps only the marked keys of a timeline, renumbering them and updating the mainline refs.
+// A ref to a removed key points to the last key kept before it.
+func (a *Animation) removeKeys(timelineIndex int, keep []bool) {
+	timeline := a.Timelines[timelineIndex]
+	newIndices := make([]int, len(keep))
+	keys := make([]*TimelineKey, 0, len(timeline.Keys))
+	for k, key := range timeline.Keys {
+		if keep[k] {
+			key.Id = len(keys)
+			keys = append(keys, key)
+		}
+		newIndices[k] = len(keys) - 1
+	}
+	timeline.Keys = keys
+
+	for _, key := range a.Mainline.Keys {
+		for _, refs := range [][]*ObjectRef{key.BoneRefs, key.ObjectRefs} {
+			for _, ref := range refs {
+				if ref.Timeline == timelineIndex && ref.Key >= 0 && ref.Key < len(newIndices) {
+					ref.Key = newIndices[ref.Key]
+				}
+			}
+		}
+	}
+}
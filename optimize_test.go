@@ -0,0 +1,78 @@
+package spriter
+
+import (
+	"fmt"
+	"testing"
+)
+
+// A bone moving from x=0 to x=100 through a middle key at x=50, with the given curve on the middle key
+func makeReductionTestModel(middleCurve string) string {
+	return fmt.Sprintf(`<spriter_data scml_version="1.0">
+<entity id="0" name="e">
+	<animation id="0" name="a" length="200" looping="false">
+		<mainline><key id="0" time="0"><bone_ref id="0" timeline="0" key="0"/></key></mainline>
+		<timeline id="0" name="b" object_type="bone">
+			<key id="0" time="0"><bone x="0" y="0" angle="0"/></key>
+			<key id="1" time="100" %s><bone x="50" y="0" angle="0"/></key>
+			<key id="2" time="200"><bone x="100" y="0" angle="0"/></key>
+		</timeline>
+	</animation>
+</entity>
+</spriter_data>`, middleCurve)
+}
+
+func TestReduceKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve string
+		keys  int
+	}{
+		{"linear", ``, 2},
+		{"cubic", `curve_type="cubic" c1="0" c2="1"`, 3},
+		{"quadratic", `curve_type="quadratic" c1="0.9"`, 3},
+		{"instant", `curve_type="instant"`, 3},
+		{"easing", `curve_type="bounce"`, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model := loadTestModel(t, makeReductionTestModel(test.curve))
+			report := ReduceKeys(model, MakeKeyReductionOptions())
+			if report.KeysBefore != 3 || report.KeysAfter != test.keys {
+				t.Fatalf("expected 3 -> %d keys, got %d -> %d", test.keys, report.KeysBefore, report.KeysAfter)
+			}
+		})
+	}
+}
+
+func TestReduceKeysKeepsPoses(t *testing.T) {
+	original := loadTestModel(t, makeReductionTestModel(`curve_type="cubic" c1="0" c2="1"`))
+	reduced := loadTestModel(t, makeReductionTestModel(`curve_type="cubic" c1="0" c2="1"`))
+	ReduceKeys(reduced, MakeKeyReductionOptions())
+	if differences := ComparePoses(original, reduced, 60, 0.001); len(differences) > 0 {
+		t.Fatalf("the poses changed: %s", differences[0].String())
+	}
+}
+
+func TestReduceKeysPivotTolerance(t *testing.T) {
+	scml := `<spriter_data scml_version="1.0">
+<folder id="0"><file id="0" name="a.png" width="10" height="10"/></folder>
+<entity id="0" name="e">
+	<animation id="0" name="a" length="200" looping="false">
+		<mainline><key id="0" time="0"><object_ref id="0" timeline="0" key="0"/></key></mainline>
+		<timeline id="0" name="o">
+			<key id="0" time="0"><object folder="0" file="0" pivot_x="0" pivot_y="0"/></key>
+			<key id="1" time="100"><object folder="0" file="0" pivot_x="0.6" pivot_y="0"/></key>
+			<key id="2" time="200"><object folder="0" file="0" pivot_x="1" pivot_y="0"/></key>
+		</timeline>
+	</animation>
+</entity>
+</spriter_data>`
+	options := MakeKeyReductionOptions()
+	if report := ReduceKeys(loadTestModel(t, scml), options); report.KeysAfter != 3 {
+		t.Fatalf("the middle key moves the pivot by 0.1, expected it to be kept, got %d keys", report.KeysAfter)
+	}
+	options.Pivot = 0.2
+	if report := ReduceKeys(loadTestModel(t, scml), options); report.KeysAfter != 2 {
+		t.Fatalf("expected the middle key to be removed with a pivot tolerance of 0.2, got %d keys", report.KeysAfter)
+	}
+}
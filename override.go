@@ -0,0 +1,162 @@
+package spriter
+
+import "math"
+
+// OverrideSpace tells in which coordinate space the values of a BoneOverride are expressed
+type OverrideSpace int
+
+const (
+	// The values are relative to the parent bone
+	OverrideLocal OverrideSpace = iota
+	// The values are in world coordinates
+	OverrideWorld
+)
+
+// OverrideMode tells how the values of a BoneOverride are combined with the animated ones
+type OverrideMode int
+
+const (
+	// The animated values are replaced by the ones of the override
+	OverrideReplace OverrideMode = iota
+	// The values of the override are added to the animated ones (the scale is multiplied)
+	OverrideAdditive
+)
+
+// BoneOverride changes the pose of a bone on top of the animation. It stays active on the player
+// until it is cleared. Only the channels that have been set are modified. Angles are in radians.
+type BoneOverride struct {
+	Space    OverrideSpace
+	Mode     OverrideMode
+	Weight   float64
+	Position *Point
+	Angle    *float64
+	Scale    *Point
+}
+
+func MakeBoneOverride(space OverrideSpace, mode OverrideMode) *BoneOverride {
+	return &BoneOverride{
+		Space:  space,
+		Mode:   mode,
+		Weight: 1,
+	}
+}
+
+func (o *BoneOverride) SetPosition(x float64, y float64) *BoneOverride {
+	o.Position = MakePoint(x, y)
+	return o
+}
+
+func (o *BoneOverride) SetAngle(angle float64) *BoneOverride {
+	o.Angle = &angle
+	return o
+}
+
+func (o *BoneOverride) SetScale(x float64, y float64) *BoneOverride {
+	o.Scale = MakePoint(x, y)
+	return o
+}
+
+func (o *BoneOverride) SetWeight(weight float64) *BoneOverride {
+	o.Weight = weight
+	return o
+}
+
+func (o *BoneOverride) apply(bone *TimelineKeyObject) {
+	w := math.Max(0, math.Min(1, o.Weight))
+	if w == 0 {
+		return
+	}
+	switch o.Mode {
+	case OverrideAdditive:
+		if o.Position != nil {
+			bone.Position[0] += o.Position.X() * w
+			bone.Position[1] += o.Position.Y() * w
+		}
+		if o.Angle != nil {
+			bone.Angle += *o.Angle * w
+		}
+		if o.Scale != nil {
+			bone.Scale[0] *= Linear(1, o.Scale.X(), w)
+			bone.Scale[1] *= Linear(1, o.Scale.Y(), w)
+		}
+	default:
+		if o.Position != nil {
+			bone.Position[0] = Linear(bone.Position.X(), o.Position.X(), w)
+			bone.Position[1] = Linear(bone.Position.Y(), o.Position.Y(), w)
+		}
+		if o.Angle != nil {
+			bone.Angle += math.Remainder(*o.Angle-bone.Angle, 2*math.Pi) * w
+		}
+		if o.Scale != nil {
+			bone.Scale[0] = Linear(bone.Scale.X(), o.Scale.X(), w)
+			bone.Scale[1] = Linear(bone.Scale.Y(), o.Scale.Y(), w)
+		}
+	}
+}
+
+// SetBoneOverride installs an override on the bone with the given name, replacing any previous one.
+// The bone is looked up by name, so the override survives animation changes.
+func (p *EntityPlayer) SetBoneOverride(name string, override *BoneOverride) {
+	if override == nil {
+		p.ClearBoneOverride(name)
+		return
+	}
+	p.boneOverrides[name] = override
+}
+
+func (p *EntityPlayer) GetBoneOverride(name string) *BoneOverride {
+	return p.boneOverrides[name]
+}
+
+func (p *EntityPlayer) ClearBoneOverride(name string) {
+	delete(p.boneOverrides, name)
+}
+
+func (p *EntityPlayer) ClearBoneOverrides() {
+	p.boneOverrides = make(map[string]*BoneOverride)
+}
+
+// resolvePose computes the world coordinates of every bone and object of the current key, starting
+// from the interpolated local coordinates. The overrides and the constraints are applied to each bone
+// before its children are unmapped, the IK chains are solved once all the bones are in place.
+// Bones are resolved in the order of the key, which has the parents before their children.
+func (p *EntityPlayer) resolvePose() {
+	for _, i := range p.currentKey.boneOrder {
+		ref := p.currentKey.BoneRefs[i]
+		local := p.interpolatedKeys[ref.Timeline].object
+		world := p.unmappedInterpolatedKeys[ref.Timeline].object
+		parent := p.getParentObject(ref)
+		name := p.animation.Timelines[ref.Timeline].Name
+		override := p.boneOverrides[name]
+
+		if override != nil && override.Space == OverrideLocal {
+			override.apply(local)
+		}
+		world.setWithBone(local)
+		world.unmapCoordinates(parent)
+		if override != nil && override.Space == OverrideWorld {
+			override.apply(world)
+			// Keep the local coordinates in sync, so the bone can be unmapped again
+			local.setWithBone(world)
+			local.mapCoordinates(parent)
+		}
+		p.applyConstraints(ref, name)
+	}
+	p.resolveIK()
+	for i := range p.currentKey.ObjectRefs {
+		p.unmapRef(p.currentKey.ObjectRefs[i])
+	}
+}
+
+func (p *EntityPlayer) getParentObject(ref *ObjectRef) *TimelineKeyObject {
+	if ref.ParentRef == nil {
+		return p.root
+	}
+	return p.unmappedInterpolatedKeys[ref.ParentRef.Timeline].object
+}
+
+func (p *EntityPlayer) unmapRef(ref *ObjectRef) {
+	world := p.unmappedInterpolatedKeys[ref.Timeline].object
+	world.setWithBone(p.interpolatedKeys[ref.Timeline].object)
+	world.unmapCoordinates(p.getParentObject(ref))
+}
@@ -0,0 +1,129 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoneOverrides(t *testing.T) {
+	// Without overrides, at the start of the walk: root at the origin rotated by 90°, upper at (0, 40)
+	// rotated by 80°, lower 60 pixels along upper
+	cos80, sin80 := math.Cos(80*math.Pi/180), math.Sin(80*math.Pi/180)
+	cos85, sin85 := math.Cos(85*math.Pi/180), math.Sin(85*math.Pi/180)
+	type pose struct{ x, y, angle float64 }
+	tests := []struct {
+		name     string
+		override *BoneOverride
+		upper    pose
+		lower    pose
+	}{
+		{"none", nil,
+			pose{0, 40, 80}, pose{60 * cos80, 40 + 60*sin80, 100}},
+		{"local replace angle", MakeBoneOverride(OverrideLocal, OverrideReplace).SetAngle(0),
+			pose{0, 40, 90}, pose{0, 100, 110}},
+		{"local additive angle", MakeBoneOverride(OverrideLocal, OverrideAdditive).SetAngle(10 * math.Pi / 180),
+			pose{0, 40, 90}, pose{0, 100, 110}},
+		{"local replace position", MakeBoneOverride(OverrideLocal, OverrideReplace).SetPosition(0, 10),
+			pose{-10, 0, 80}, pose{-10 + 60*cos80, 60 * sin80, 100}},
+		{"local additive scale", MakeBoneOverride(OverrideLocal, OverrideAdditive).SetScale(2, 1),
+			pose{0, 40, 80}, pose{120 * cos80, 40 + 120*sin80, 100}},
+		{"world replace position", MakeBoneOverride(OverrideWorld, OverrideReplace).SetPosition(100, 50),
+			pose{100, 50, 80}, pose{100 + 60*cos80, 50 + 60*sin80, 100}},
+		{"world additive position", MakeBoneOverride(OverrideWorld, OverrideAdditive).SetPosition(5, 0),
+			pose{5, 40, 80}, pose{5 + 60*cos80, 40 + 60*sin80, 100}},
+		{"world replace angle", MakeBoneOverride(OverrideWorld, OverrideReplace).SetAngle(0),
+			pose{0, 40, 0}, pose{60, 40, 20}},
+		// The local angle goes from 350° to 0° the shortest way, half of it
+		{"half weight", MakeBoneOverride(OverrideLocal, OverrideReplace).SetAngle(0).SetWeight(0.5),
+			pose{0, 40, 85}, pose{60 * cos85, 40 + 60*sin85, 105}},
+		{"half weight scale", MakeBoneOverride(OverrideLocal, OverrideAdditive).SetScale(2, 1).SetWeight(0.5),
+			pose{0, 40, 80}, pose{90 * cos80, 40 + 90*sin80, 100}},
+		{"zero weight", MakeBoneOverride(OverrideWorld, OverrideReplace).SetPosition(100, 50).SetWeight(0),
+			pose{0, 40, 80}, pose{60 * cos80, 40 + 60*sin80, 100}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+			p.SetBoneOverride("upper", test.override)
+			p.Update(0)
+			checkBone(t, p, "root", 0, 0, 90)
+			checkBone(t, p, "upper", test.upper.x, test.upper.y, test.upper.angle)
+			checkBone(t, p, "lower", test.lower.x, test.lower.y, test.lower.angle)
+		})
+	}
+}
+
+func TestBoneOverridePersists(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.SetBoneOverride("root", MakeBoneOverride(OverrideWorld, OverrideReplace).SetPosition(10, 20))
+	for _, update := range []func(){
+		func() { p.Update(0) },
+		func() { p.Update(300) },
+		func() { p.Update(300) },
+		func() { p.SetAnimationByName("idle"); p.Update(100) },
+		func() { p.SetAnimationByName("walk"); p.Update(0) },
+	} {
+		update()
+		bone := p.getBoneByName("root")
+		if bone.Position.X() != 10 || bone.Position.Y() != 20 {
+			t.Fatalf("%s at %d ms: expected the root at (10, 20), got %v", p.GetAnimation().Name, p.getTime(), bone.Position)
+		}
+	}
+}
+
+func TestClearBoneOverride(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.SetBoneOverride("upper", MakeBoneOverride(OverrideLocal, OverrideReplace).SetAngle(0))
+	p.SetBoneOverride("lower", MakeBoneOverride(OverrideLocal, OverrideAdditive).SetAngle(1))
+	p.Update(0)
+	checkBone(t, p, "upper", 0, 40, 90)
+
+	p.ClearBoneOverride("upper")
+	if p.GetBoneOverride("upper") != nil || p.GetBoneOverride("lower") == nil {
+		t.Fatal("only the override of upper must be cleared")
+	}
+	p.ClearBoneOverrides()
+	p.Update(0)
+	cos80, sin80 := math.Cos(80*math.Pi/180), math.Sin(80*math.Pi/180)
+	checkBone(t, p, "upper", 0, 40, 80)
+	checkBone(t, p, "lower", 60*cos80, 40+60*sin80, 100)
+}
+
+// The child bone ref comes before its parent
+const unsortedBonesSCML = `<spriter_data scml_version="1.0">
+    <entity id="0" name="Arm">
+        <obj_info name="upper" type="bone" w="60" h="10"/>
+        <obj_info name="lower" type="bone" w="50" h="10"/>
+        <animation id="0" name="idle" length="1000">
+            <mainline>
+                <key id="0">
+                    <bone_ref id="0" parent="1" timeline="1" key="0"/>
+                    <bone_ref id="1" timeline="0" key="0"/>
+                </key>
+            </mainline>
+            <timeline id="0" name="upper" object_type="bone">
+                <key id="0"><bone x="10" y="0" angle="90"/></key>
+            </timeline>
+            <timeline id="1" name="lower" object_type="bone">
+                <key id="0"><bone x="60" y="0" angle="0"/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+func TestUnsortedBoneRefs(t *testing.T) {
+	model := loadTestModel(t, unsortedBonesSCML)
+	decoded, err := DecodeModel(encodeTestBinaryModel(t, model), FormatBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, model := range map[string]*Model{"scml": model, "binary": decoded} {
+		t.Run(name, func(t *testing.T) {
+			p := MakeEntityPlayer(model.Entities[0])
+			p.SetBoneOverride("upper", MakeBoneOverride(OverrideLocal, OverrideAdditive).SetAngle(math.Pi/2))
+			p.Update(0)
+			checkBone(t, p, "upper", 10, 0, 180)
+			checkBone(t, p, "lower", -50, 0, 180)
+		})
+	}
+}
@@ -59,10 +59,11 @@ func (p *Point) Sub(b *Point) *Point {
 	return p
 }
 
-func (p *Point) Rotate(degrees float64) *Point {
+// Rotate rotates the point around the origin, counterclockwise. The angle is in radians.
+func (p *Point) Rotate(radians float64) *Point {
 	if p[0] != 0 || p[1] != 0 {
-		cos := math.Cos(degrees)
-		sin := math.Sin(degrees)
+		cos := math.Cos(radians)
+		sin := math.Sin(radians)
 
 		xx := p[0]*cos - p[1]*sin
 		yy := p[0]*sin + p[1]*cos
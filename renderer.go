@@ -0,0 +1,161 @@
+package spriter
+
+import "sort"
+
+type Color struct {
+	R float64
+	G float64
+	B float64
+	A float64
+}
+
+func MakeColor(r float64, g float64, b float64, a float64) Color {
+	return Color{R: r, G: g, B: b, A: a}
+}
+
+var White = MakeColor(1, 1, 1, 1)
+
+// DrawCommand contains everything needed to draw a sprite, independently of the rendering library
+type DrawCommand struct {
+	// Name of the timeline of the sprite
+	Name string
+	// Index of the file in the model, or of an external texture (see ExternalFileIndex)
+	FileIndex int
+	File      *File
+	// Transformation from the pixels of the image (origin on the top-left corner, Y axis pointing down)
+	// to world coordinates (Y axis pointing up, like in Spriter)
+	Transform Matrix
+	Position  Point
+	Angle     float64
+	Scale     Point
+	// Pivot in pixels, relative to the top-left corner of the image
+	PivotX float64
+	PivotY float64
+	Alpha  float64
+	Tint   Color
+	Z      int
+	FlipX  bool
+	FlipY  bool
+}
+
+// Width and height of the image, before scaling
+func (c *DrawCommand) Size() (float64, float64) {
+	return float64(c.File.Width), float64(c.File.Height)
+}
+
+// Corners returns the corners of the image in world coordinates: top-left, top-right, bottom-right, bottom-left
+func (c *DrawCommand) Corners() [4]Point {
+	w, h := c.Size()
+	corners := [4]Point{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	for i := range corners {
+		c.Transform.ApplyToPoint(&corners[i])
+	}
+	return corners
+}
+
+// Renderer draws the commands produced by a player, in the order they are given
+type Renderer interface {
+	Render(commands []DrawCommand)
+}
+
+// DrawBatch is a sequence of commands which use the same texture
+type DrawBatch struct {
+	FileIndex int
+	Commands  []DrawCommand
+}
+
+func (p *EntityPlayer) SetTint(tint Color) *EntityPlayer {
+	p.tint = tint
+	return p
+}
+
+func (p *EntityPlayer) GetTint() Color {
+	return p.tint
+}
+
+// Draw sends the draw commands of the current pose to the renderer
+func (p *EntityPlayer) Draw(renderer Renderer) {
+	renderer.Render(p.GetDrawCommands())
+}
+
+// GetDrawCommands returns the commands to draw the visible sprites of the current pose, sorted by z_index
+func (p *EntityPlayer) GetDrawCommands() []DrawCommand {
+	return p.AppendDrawCommands(nil)
+}
+
+// AppendDrawCommands appends the commands of the current pose to `commands`, so that a slice can be reused
+// across frames
+func (p *EntityPlayer) AppendDrawCommands(commands []DrawCommand) []DrawCommand {
+	order := p.GetDrawOrder()
+	for i := range order {
+		ref := p.currentKey.ObjectRefs[order[i]]
+		object := p.unmappedInterpolatedKeys[ref.Timeline].object
+		if !isVisibleSprite(object) {
+			continue
+		}
+		fileIndex := p.GetMappedFileIndexForKeyObject(object)
+		file := p.GetMappedFileForKeyObject(object)
+		if file == nil {
+			continue
+		}
+		commands = append(commands, makeDrawCommand(object, fileIndex, file))
+		command := &commands[len(commands)-1]
+		command.Name = p.animation.Timelines[ref.Timeline].Name
+		command.Tint = p.tint
+		command.Z = ref.zIndex()
+	}
+	return commands
+}
+
+// isVisibleSprite tells if an object is drawn: sprites which are fully transparent are skipped, when drawing
+// and when hit testing
+func isVisibleSprite(object *TimelineKeyObject) bool {
+	return object.objectType == TypeSprite && object.Alpha > 0
+}
+
+func makeDrawCommand(object *TimelineKeyObject, fileIndex int, file *File) DrawCommand {
+	w, h := float64(file.Width), float64(file.Height)
+	pivotX := object.Pivot.X() * w
+	pivotY := (1 - object.Pivot.Y()) * h
+	// From image pixels to the space of the sprite (origin on the pivot, Y pointing up)
+	imageToSprite := Matrix{1, 0, 0, -1, -pivotX, pivotY}
+	transform := MakeTransformMatrix(object.Position.X(), object.Position.Y(), object.Angle, object.Scale.X(), object.Scale.Y())
+	transform.Multiply(&imageToSprite)
+
+	return DrawCommand{
+		FileIndex: fileIndex,
+		File:      file,
+		Transform: *transform,
+		Position:  *object.Position,
+		Angle:     object.Angle,
+		Scale:     *object.Scale,
+		PivotX:    pivotX,
+		PivotY:    pivotY,
+		Alpha:     object.Alpha,
+		Tint:      White,
+		FlipX:     object.Scale.X() < 0,
+		FlipY:     object.Scale.Y() < 0,
+	}
+}
+
+// GroupByTexture splits the commands into batches of consecutive commands using the same file.
+// The drawing order is preserved.
+func GroupByTexture(commands []DrawCommand) []DrawBatch {
+	batches := make([]DrawBatch, 0)
+	start := 0
+	for i := 1; i <= len(commands); i++ {
+		if i == len(commands) || commands[i].FileIndex != commands[start].FileIndex {
+			batches = append(batches, DrawBatch{FileIndex: commands[start].FileIndex, Commands: commands[start:i]})
+			start = i
+		}
+	}
+	return batches
+}
+
+// SortByTexture sorts the commands by file, keeping the z order between commands of the same file.
+// The drawing order is lost: use it only with renderers which use Z for depth testing.
+func SortByTexture(commands []DrawCommand) {
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].FileIndex < commands[j].FileIndex
+	})
+}
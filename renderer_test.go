@@ -0,0 +1,109 @@
+package spriter
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMakeDrawCommand(t *testing.T) {
+	file := &File{Width: 40, Height: 20}
+	tests := []struct {
+		name  string
+		angle float64
+		scale Point
+		// World coordinates of the top-left corner of the image and of its pivot
+		topLeft Point
+		pivot   Point
+		flipX   bool
+		flipY   bool
+	}{
+		{"pivot", 0, Point{1, 1}, Point{90, 60}, Point{100, 50}, false, false},
+		{"scale", 0, Point{2, 3}, Point{80, 80}, Point{100, 50}, false, false},
+		{"flip x", 0, Point{-1, 1}, Point{110, 60}, Point{100, 50}, true, false},
+		{"flip y", 0, Point{1, -1}, Point{90, 40}, Point{100, 50}, false, true},
+		{"rotation", math.Pi / 2, Point{1, 1}, Point{90, 40}, Point{100, 50}, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object := MakeTimelineKeyObject()
+			object.objectType = TypeSprite
+			object.Position = MakePoint(100, 50)
+			object.Angle = test.angle
+			object.Scale = MakePoint(test.scale.X(), test.scale.Y())
+			// A quarter of the width from the left, half the height from the bottom
+			object.Pivot = MakePoint(0.25, 0.5)
+			object.Alpha = 0.5
+
+			command := makeDrawCommand(object, 3, file)
+			if command.PivotX != 10 || command.PivotY != 10 {
+				t.Errorf("expected the pivot at (10, 10) pixels, got (%g, %g)", command.PivotX, command.PivotY)
+			}
+			for _, check := range []struct {
+				pixel    Point
+				expected Point
+			}{{Point{0, 0}, test.topLeft}, {Point{10, 10}, test.pivot}} {
+				x, y := command.Transform.Apply(check.pixel.X(), check.pixel.Y())
+				if math.Abs(x-check.expected.X()) > 1e-9 || math.Abs(y-check.expected.Y()) > 1e-9 {
+					t.Errorf("pixel %v: expected %v, got (%g, %g)", check.pixel, check.expected, x, y)
+				}
+			}
+			if command.FlipX != test.flipX || command.FlipY != test.flipY {
+				t.Errorf("expected flip (%v, %v), got (%v, %v)", test.flipX, test.flipY, command.FlipX, command.FlipY)
+			}
+			if command.FileIndex != 3 || command.File != file || command.Alpha != 0.5 {
+				t.Errorf("unexpected command %+v", command)
+			}
+		})
+	}
+}
+
+func makeTestCommands(fileIndices ...int) []DrawCommand {
+	commands := make([]DrawCommand, len(fileIndices))
+	for i := range commands {
+		commands[i] = DrawCommand{FileIndex: fileIndices[i], Z: i}
+	}
+	return commands
+}
+
+func getCommandsZ(commands []DrawCommand) []int {
+	z := make([]int, len(commands))
+	for i := range commands {
+		z[i] = commands[i].Z
+	}
+	return z
+}
+
+func TestGroupByTexture(t *testing.T) {
+	if batches := GroupByTexture(nil); len(batches) != 0 {
+		t.Errorf("expected no batches, got %d", len(batches))
+	}
+
+	commands := makeTestCommands(1, 1, 2, 1, 3, 3)
+	batches := GroupByTexture(commands)
+	var files []int
+	var drawn []DrawCommand
+	for _, batch := range batches {
+		files = append(files, batch.FileIndex)
+		for _, command := range batch.Commands {
+			if command.FileIndex != batch.FileIndex {
+				t.Errorf("command of file %d in the batch of file %d", command.FileIndex, batch.FileIndex)
+			}
+		}
+		drawn = append(drawn, batch.Commands...)
+	}
+	if expected := []int{1, 2, 1, 3}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected batches of the files %v, got %v", expected, files)
+	}
+	if z := getCommandsZ(drawn); !reflect.DeepEqual(z, []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("the batches must keep the drawing order, got %v", z)
+	}
+}
+
+func TestSortByTexture(t *testing.T) {
+	commands := makeTestCommands(2, 1, 2, 1, 0, 2)
+	SortByTexture(commands)
+	if z := getCommandsZ(commands); !reflect.DeepEqual(z, []int{4, 1, 3, 0, 2, 5}) {
+		t.Errorf("expected the commands sorted by file, then by z, got %v", z)
+	}
+}
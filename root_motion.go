@@ -0,0 +1,205 @@
+package spriter
+
+import (
+	"fmt"
+	"math"
+)
+
+// RootMotion extracts the movement of a bone (usually the root of the skeleton) from the animation.
+// The extracted channels are removed from the rendered pose, the bone stays where it is at the
+// beginning of the animation, and the movement of the last Update is made available as a delta
+// in world coordinates, so that the entity can be moved by the game instead.
+// The bone must have no parent: its motion is extracted in the space of the player.
+// In a looping animation the bone returns to its first key after the last one: that return is not motion,
+// the bone is considered still after its last key and each loop adds the movement from the first key to the last.
+type RootMotion struct {
+	Bone         string
+	ExtractX     bool
+	ExtractY     bool
+	ExtractAngle bool
+	// Movement of the last update, in world coordinates
+	Delta      *Point
+	DeltaAngle float64
+
+	reference    *TimelineKeyObject
+	sampled      *TimelineKeyObject
+	lastPosition *Point
+	lastAngle    float64
+	hasLast      bool
+	wraps        int
+}
+
+func MakeRootMotion(bone string) *RootMotion {
+	return &RootMotion{
+		Bone:         bone,
+		ExtractX:     true,
+		ExtractY:     true,
+		ExtractAngle: true,
+		Delta:        MakePoint(0, 0),
+		sampled:      MakeTimelineKeyBone(),
+		lastPosition: MakePoint(0, 0),
+	}
+}
+
+func (r *RootMotion) reset() {
+	r.reference = nil
+	r.hasLast = false
+	r.wraps = 0
+	r.Delta.SetCoords(0, 0)
+	r.DeltaAngle = 0
+}
+
+// sample interpolates the timeline of the bone at the given time, without evaluating the rest of the animation,
+// and returns its coordinates in the space of the player
+func (r *RootMotion) sample(a *Animation, time int) (*Point, float64, bool) {
+	timeline := a.getTimelineByName(r.Bone)
+	if timeline == nil {
+		return nil, 0, false
+	}
+	if a.Looping && len(timeline.Keys) > 0 {
+		time = minInt(time, timeline.Keys[len(timeline.Keys)-1].Time)
+	}
+	key := a.Mainline.getKeyBeforeTime(time)
+	for _, ref := range key.BoneRefs {
+		if ref.Timeline == timeline.Id && ref.ParentRef == nil {
+			a.interpolateRef(ref, key, time, r.sampled)
+			return r.sampled.Position.MakeCopy(), r.sampled.Angle, true
+		}
+	}
+	return nil, 0, false
+}
+
+// update computes the delta between the previous update and the given time, taking into account
+// the loops of the animation happened in between
+func (r *RootMotion) update(a *Animation, time int, root *TimelineKeyObject) {
+	r.Delta.SetCoords(0, 0)
+	r.DeltaAngle = 0
+	if r.reference == nil {
+		position, angle, ok := r.sample(a, 0)
+		if !ok {
+			return
+		}
+		r.reference = MakeTimelineKeyBone()
+		r.reference.Position.Set(position)
+		r.reference.Angle = angle
+	}
+
+	delta := MakePoint(0, 0)
+	deltaAngle := 0.0
+	if r.hasLast && r.wraps != 0 {
+		// The animation looped: move to the end (or the start), add the full loops and jump back to the other side
+		from, to := a.Length, 0
+		if r.wraps < 0 {
+			from, to = 0, a.Length
+		}
+		fromPosition, fromAngle, okFrom := r.sample(a, from)
+		toPosition, toAngle, okTo := r.sample(a, to)
+		if okFrom && okTo {
+			loops := r.wraps
+			if loops < 0 {
+				loops = -loops
+			}
+			delta.Add(fromPosition).Sub(r.lastPosition)
+			deltaAngle += math.Remainder(fromAngle-r.lastAngle, 2*math.Pi)
+			loop := MakePoint(fromPosition.X()-toPosition.X(), fromPosition.Y()-toPosition.Y())
+			delta.Add(loop.ScaleCoords(float64(loops-1), float64(loops-1)))
+			deltaAngle += float64(loops-1) * math.Remainder(fromAngle-toAngle, 2*math.Pi)
+			r.lastPosition.Set(toPosition)
+			r.lastAngle = toAngle
+		}
+	}
+	r.wraps = 0
+
+	position, angle, ok := r.sample(a, time)
+	if !ok {
+		r.hasLast = false
+		return
+	}
+	if r.hasLast {
+		delta.Add(position).Sub(r.lastPosition)
+		deltaAngle += math.Remainder(angle-r.lastAngle, 2*math.Pi)
+	}
+	r.lastPosition.Set(position)
+	r.lastAngle = angle
+	r.hasLast = true
+
+	if !r.ExtractX {
+		delta[0] = 0
+	}
+	if !r.ExtractY {
+		delta[1] = 0
+	}
+	if !r.ExtractAngle {
+		deltaAngle = 0
+	}
+	// From the space of the player to the world
+	delta.Scale(root.Scale)
+	delta.Rotate(root.Angle)
+	r.Delta.Set(delta)
+	r.DeltaAngle = deltaAngle * signum(root.Scale.X()) * signum(root.Scale.Y())
+}
+
+// removeFrom brings the extracted channels of the bone back to the beginning of the animation
+func (r *RootMotion) removeFrom(bone *TimelineKeyObject) {
+	if r.reference == nil {
+		return
+	}
+	if r.ExtractX {
+		bone.Position[0] = r.reference.Position.X()
+	}
+	if r.ExtractY {
+		bone.Position[1] = r.reference.Position.Y()
+	}
+	if r.ExtractAngle {
+		bone.Angle = r.reference.Angle
+	}
+}
+
+// SetRootMotion enables the extraction of the root motion. Passing nil disables it.
+// An error is returned, and the root motion is not changed, if the bone has a parent in an animation of the entity.
+func (p *EntityPlayer) SetRootMotion(rootMotion *RootMotion) error {
+	if rootMotion != nil {
+		for _, animation := range p.entity.Animations {
+			for _, key := range animation.Mainline.Keys {
+				for _, ref := range key.BoneRefs {
+					if ref.ParentRef != nil && animation.Timelines[ref.Timeline].Name == rootMotion.Bone {
+						return fmt.Errorf("root motion: bone '%s' has a parent in animation '%s'", rootMotion.Bone, animation.Name)
+					}
+				}
+			}
+		}
+		rootMotion.reset()
+	}
+	p.rootMotion = rootMotion
+	return nil
+}
+
+func (p *EntityPlayer) GetRootMotion() *RootMotion {
+	return p.rootMotion
+}
+
+// RootMotionDelta returns the movement extracted by the last Update, in world coordinates
+func (p *EntityPlayer) RootMotionDelta() (float64, float64, float64) {
+	if p.rootMotion == nil {
+		return 0, 0, 0
+	}
+	return p.rootMotion.Delta.X(), p.rootMotion.Delta.Y(), p.rootMotion.DeltaAngle
+}
+
+func (p *EntityPlayer) updateRootMotion() {
+	if p.rootMotion == nil {
+		return
+	}
+	p.rootMotion.update(p.animation, p.time, p.root)
+}
+
+func (p *EntityPlayer) removeRootMotion() {
+	if p.rootMotion == nil {
+		return
+	}
+	timeline := p.animation.getTimelineByName(p.rootMotion.Bone)
+	if timeline == nil || !p.unmappedInterpolatedKeys[timeline.Id].active {
+		return
+	}
+	p.rootMotion.removeFrom(p.interpolatedKeys[timeline.Id].object)
+}
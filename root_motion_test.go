@@ -0,0 +1,127 @@
+package spriter
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// A non looping animation where the root moves by 100 and turns by 10°, replayed in loops by the player
+const rootMotionTestSCML = `<spriter_data scml_version="1.0">
+<entity id="0" name="e">
+	<animation id="0" name="walk" length="1000" looping="false">
+		<mainline><key id="0" time="0">
+			<bone_ref id="0" timeline="0" key="0"/>
+			<bone_ref id="1" parent="0" timeline="1" key="0"/>
+		</key></mainline>
+		<timeline id="0" name="root" object_type="bone">
+			<key id="0" time="0"><bone x="0" y="0" angle="0"/></key>
+			<key id="1" time="1000"><bone x="100" y="0" angle="10"/></key>
+		</timeline>
+		<timeline id="1" name="child" object_type="bone">
+			<key id="0" time="0"><bone x="10" y="0" angle="0"/></key>
+		</timeline>
+	</animation>
+</entity>
+</spriter_data>`
+
+// The same motion in a looping animation: the root goes back to its first key between 1000 and 1250ms
+var loopingRootMotionTestSCML = strings.Replace(rootMotionTestSCML, `length="1000" looping="false"`, `length="1250"`, 1)
+
+// playRootMotion plays the animation with the given updates and returns the sum of the root motion deltas
+func playRootMotion(t *testing.T, scml string, updates []int) (float64, float64, float64) {
+	t.Helper()
+	p := MakeEntityPlayer(loadTestModel(t, scml).Entities[0])
+	if err := p.SetRootMotion(MakeRootMotion("root")); err != nil {
+		t.Fatal(err)
+	}
+	var x, y, angle float64
+	for _, update := range append(updates, 0) {
+		p.Update(update)
+		dx, dy, dAngle := p.RootMotionDelta()
+		x, y, angle = x+dx, y+dy, angle+dAngle
+	}
+	return x, y, angle * 180 / math.Pi
+}
+
+func TestRootMotionLoops(t *testing.T) {
+	steps := make([]int, 25)
+	for i := range steps {
+		steps[i] = 100
+	}
+	backSteps := make([]int, 25)
+	for i := range backSteps {
+		backSteps[i] = -100
+	}
+	tests := []struct {
+		name    string
+		updates []int
+		x       float64
+		angle   float64
+	}{
+		{"no loop", []int{300, 300}, 60, 6},
+		{"one loop", []int{700, 700}, 140, 14},
+		{"small steps", steps, 250, 25},
+		{"several loops in one update", []int{2500}, 250, 25},
+		{"several loops backwards", []int{-2500}, -250, -25},
+		{"small steps backwards", backSteps, -250, -25},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, y, angle := playRootMotion(t, rootMotionTestSCML, test.updates)
+			if math.Abs(x-test.x) > 1e-9 || math.Abs(y) > 1e-9 || math.Abs(angle-test.angle) > 1e-9 {
+				t.Fatalf("expected a motion of (%g, 0, %g°), got (%g, %g, %g°)", test.x, test.angle, x, y, angle)
+			}
+		})
+	}
+}
+
+func TestRootMotionLoopingAnimation(t *testing.T) {
+	steps := make([]int, 20)
+	for i := range steps {
+		steps[i] = 125
+	}
+	tests := []struct {
+		name    string
+		updates []int
+		x       float64
+		angle   float64
+	}{
+		{"no loop", []int{300, 300}, 60, 6},
+		{"after the last key", []int{1100}, 100, 10},
+		{"one loop", []int{700, 700}, 115, 11.5},
+		{"small steps", steps, 200, 20},
+		{"several loops in one update", []int{2500, 600}, 260, 26},
+		{"several loops backwards", []int{-2500}, -200, -20},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, y, angle := playRootMotion(t, loopingRootMotionTestSCML, test.updates)
+			if math.Abs(x-test.x) > 1e-9 || math.Abs(y) > 1e-9 || math.Abs(angle-test.angle) > 1e-9 {
+				t.Fatalf("expected a motion of (%g, 0, %g°), got (%g, %g, %g°)", test.x, test.angle, x, y, angle)
+			}
+		})
+	}
+}
+
+func TestRootMotionRemovedFromPose(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, rootMotionTestSCML).Entities[0])
+	if err := p.SetRootMotion(MakeRootMotion("root")); err != nil {
+		t.Fatal(err)
+	}
+	p.Update(500)
+	p.Update(0)
+	if root := p.getBoneByName("root"); root.Position.X() != 0 || root.Angle != 0 {
+		t.Fatalf("the root must stay at the origin, got (%g, %g°)", root.Position.X(), root.Angle*180/math.Pi)
+	}
+}
+
+func TestRootMotionNeedsParentlessBone(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, rootMotionTestSCML).Entities[0])
+	if err := p.SetRootMotion(MakeRootMotion("child")); err == nil {
+		t.Fatal("a bone with a parent must be rejected")
+	}
+	if p.GetRootMotion() != nil {
+		t.Fatal("a rejected root motion must not be set")
+	}
+}
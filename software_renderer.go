@@ -0,0 +1,225 @@
+package spriter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// SoftwareRenderer draws the commands on an image.RGBA, without a GPU.
+// The images of the files are loaded from the folder of the model.
+type SoftwareRenderer struct {
+	Target *image.RGBA
+	// Transformation from world coordinates to the pixels of the target.
+	// By default the origin is in the center of the image and the Y axis points up.
+	View   Matrix
+	model  *Model
+	images map[int]*image.RGBA
+	err    error
+}
+
+func MakeSoftwareRenderer(model *Model, target *image.RGBA) *SoftwareRenderer {
+	r := &SoftwareRenderer{
+		Target: target,
+		model:  model,
+		images: make(map[int]*image.RGBA),
+	}
+	bounds := target.Bounds()
+	r.View = Matrix{1, 0, 0, -1, float64(bounds.Min.X) + float64(bounds.Dx())/2, float64(bounds.Min.Y) + float64(bounds.Dy())/2}
+	return r
+}
+
+// LoadImages loads the images of all the files of the model
+func (r *SoftwareRenderer) LoadImages() error {
+	for fileIndex, file := range r.model.Files {
+		if _, ok := r.images[fileIndex]; ok {
+			continue
+		}
+		if err := r.loadImage(fileIndex, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetImage sets the image used for a file, instead of loading it from disk. External textures, see
+// ExternalFileIndex, are drawn only if their image has been set.
+func (r *SoftwareRenderer) SetImage(fileIndex int, img image.Image) {
+	r.images[fileIndex] = toRGBA(img)
+}
+
+// loadFileImage loads the image of a file of the model, unless it has already been loaded or set.
+// External textures are skipped.
+func (r *SoftwareRenderer) loadFileImage(fileIndex int) error {
+	if _, ok := r.images[fileIndex]; ok || IsExternalFileIndex(fileIndex) {
+		return nil
+	}
+	file := r.model.GetFile(fileIndex)
+	if file == nil {
+		return fmt.Errorf("file %d not found in the model", fileIndex)
+	}
+	return r.loadImage(fileIndex, file)
+}
+
+func (r *SoftwareRenderer) loadImage(fileIndex int, file *File) error {
+	f, err := os.Open(filepath.Join(r.model.GetDir(), file.Name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", file.Name, err)
+	}
+	r.images[fileIndex] = toRGBA(img)
+	return nil
+}
+
+func (r *SoftwareRenderer) getImage(command *DrawCommand) *image.RGBA {
+	img, ok := r.images[command.FileIndex]
+	// External textures are not in the folder of the model, they can only be given with SetImage
+	if !ok && !IsExternalFileIndex(command.FileIndex) {
+		if err := r.loadImage(command.FileIndex, command.File); err != nil && r.err == nil {
+			r.err = err
+		}
+		// A missing image is not loaded again
+		img = r.images[command.FileIndex]
+		r.images[command.FileIndex] = img
+	}
+	return img
+}
+
+// Err returns the first error loading an image while drawing. Sprites whose image can't be loaded are skipped.
+func (r *SoftwareRenderer) Err() error {
+	return r.err
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min.Eq(image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Clear fills the target with a color
+func (r *SoftwareRenderer) Clear(c color.Color) {
+	draw.Draw(r.Target, r.Target.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// Render draws the commands in order, with bilinear sampling and alpha blending
+func (r *SoftwareRenderer) Render(commands []DrawCommand) {
+	for i := range commands {
+		r.drawCommand(&commands[i])
+	}
+}
+
+func (r *SoftwareRenderer) drawCommand(command *DrawCommand) {
+	img := r.getImage(command)
+	if img == nil {
+		return
+	}
+	alpha := command.Alpha * command.Tint.A
+	if alpha <= 0 {
+		return
+	}
+	// From the pixels of the image to the pixels of the target
+	transform := r.View
+	transform.Multiply(&command.Transform)
+	inverse := transform.Invert()
+	if inverse == nil {
+		return
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	area := MakeEmptyRect()
+	for _, corner := range [4]Point{{0, 0}, {float64(width), 0}, {float64(width), float64(height)}, {0, float64(height)}} {
+		x, y := transform.Apply(corner.X(), corner.Y())
+		area.AddPoint(x, y)
+	}
+	target := r.Target.Bounds()
+	minX := int(math.Max(math.Floor(area.MinX), float64(target.Min.X)))
+	minY := int(math.Max(math.Floor(area.MinY), float64(target.Min.Y)))
+	maxX := int(math.Min(math.Ceil(area.MaxX), float64(target.Max.X)))
+	maxY := int(math.Min(math.Ceil(area.MaxY), float64(target.Max.Y)))
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			u, v := inverse.Apply(float64(x)+0.5, float64(y)+0.5)
+			if u < 0 || v < 0 || u >= float64(width) || v >= float64(height) {
+				continue
+			}
+			sr, sg, sb, sa := sampleBilinear(img, u, v)
+			if sa <= 0 {
+				continue
+			}
+			// Colors are premultiplied
+			sr *= command.Tint.R * alpha
+			sg *= command.Tint.G * alpha
+			sb *= command.Tint.B * alpha
+			sa *= alpha
+			offset := r.Target.PixOffset(x, y)
+			pix := r.Target.Pix[offset : offset+4 : offset+4]
+			pix[0] = blend(sr, pix[0], sa)
+			pix[1] = blend(sg, pix[1], sa)
+			pix[2] = blend(sb, pix[2], sa)
+			pix[3] = blend(sa, pix[3], sa)
+		}
+	}
+}
+
+func blend(src float64, dst uint8, srcAlpha float64) uint8 {
+	value := src*255 + float64(dst)*(1-srcAlpha)
+	return uint8(math.Max(0, math.Min(255, math.Round(value))))
+}
+
+// sampleBilinear returns the premultiplied color of the image at (u,v), in the range [0,1].
+// Pixel centers are at half coordinates, the border pixels are extended.
+func sampleBilinear(img *image.RGBA, u float64, v float64) (float64, float64, float64, float64) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	u -= 0.5
+	v -= 0.5
+	x0 := int(math.Floor(u))
+	y0 := int(math.Floor(v))
+	fx := u - float64(x0)
+	fy := v - float64(y0)
+
+	var result [4]float64
+	for _, sample := range [4]struct {
+		x, y   int
+		weight float64
+	}{
+		{x0, y0, (1 - fx) * (1 - fy)},
+		{x0 + 1, y0, fx * (1 - fy)},
+		{x0, y0 + 1, (1 - fx) * fy},
+		{x0 + 1, y0 + 1, fx * fy},
+	} {
+		if sample.weight == 0 {
+			continue
+		}
+		x := clampInt(sample.x, 0, width-1)
+		y := clampInt(sample.y, 0, height-1)
+		offset := img.PixOffset(x, y)
+		for c := 0; c < 4; c++ {
+			result[c] += float64(img.Pix[offset+c]) * sample.weight
+		}
+	}
+	return result[0] / 255, result[1] / 255, result[2] / 255, result[3] / 255
+}
+
+func clampInt(value int, min int, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,134 @@
+package spriter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSoftwareRendererMissingImages(t *testing.T) {
+	model := loadTestModel(t, testHeroSCML)
+	model.dir = t.TempDir()
+	p := MakeEntityPlayer(model.Entities[0])
+	p.Update(0)
+
+	renderer := MakeSoftwareRenderer(model, image.NewRGBA(image.Rect(0, 0, 200, 200)))
+	p.Draw(renderer)
+	if renderer.Err() == nil {
+		t.Fatal("expected an error for the images missing from the folder of the model")
+	}
+
+	writeTestImages(t, model)
+	renderer = MakeSoftwareRenderer(model, image.NewRGBA(image.Rect(0, 0, 200, 200)))
+	p.Draw(renderer)
+	if err := renderer.Err(); err != nil {
+		t.Fatal(err)
+	}
+	drawn := false
+	for i := 3; i < len(renderer.Target.Pix); i += 4 {
+		drawn = drawn || renderer.Target.Pix[i] != 0
+	}
+	if !drawn {
+		t.Error("expected the sprites to be drawn")
+	}
+}
+
+// A sprite of 4x2 pixels: red and blue on the top row, green and white on the bottom one, two pixels each
+const softwareRendererTestSCML = `<spriter_data scml_version="1.0">
+    <folder id="0">
+        <file id="0" name="quad.png" width="4" height="2" pivot_x="0" pivot_y="0"/>
+    </folder>
+    <entity id="0" name="e">
+        <animation id="0" name="a" length="1000">
+            <mainline>
+                <key id="0"><object_ref id="0" timeline="0" key="0" z_index="0"/></key>
+            </mainline>
+            <timeline id="0" name="quad">
+                <key id="0"><object folder="0" file="0" %s/></key>
+            </timeline>
+        </animation>
+    </entity>
+</spriter_data>`
+
+var (
+	testRed   = color.RGBA{255, 0, 0, 255}
+	testBlue  = color.RGBA{0, 0, 255, 255}
+	testGreen = color.RGBA{0, 255, 0, 255}
+	testWhite = color.RGBA{255, 255, 255, 255}
+	testClear = color.RGBA{}
+)
+
+func makeTestQuadImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		top, bottom := testRed, testGreen
+		if x >= 2 {
+			top, bottom = testBlue, testWhite
+		}
+		img.SetRGBA(x, 0, top)
+		img.SetRGBA(x, 1, bottom)
+	}
+	return img
+}
+
+func TestSoftwareRendererPixels(t *testing.T) {
+	// The target is 20x20: the world origin is on the pixel (10,10), Y points up
+	tests := []struct {
+		name       string
+		object     string
+		background color.RGBA
+		pixels     map[image.Point]color.RGBA
+	}{
+		{"bottom left pivot", `x="0" y="0"`, testClear, map[image.Point]color.RGBA{
+			{10, 8}: testRed, {11, 8}: testRed, {12, 8}: testBlue, {13, 8}: testBlue,
+			{10, 9}: testGreen, {13, 9}: testWhite,
+			{9, 9}: testClear, {14, 9}: testClear, {10, 7}: testClear, {10, 10}: testClear,
+		}},
+		{"centered pivot", `x="0" y="0" pivot_x="0.5" pivot_y="0.5"`, testClear, map[image.Point]color.RGBA{
+			{8, 9}: testRed, {11, 9}: testBlue, {8, 10}: testGreen, {11, 10}: testWhite,
+			{7, 9}: testClear, {12, 10}: testClear, {8, 8}: testClear, {8, 11}: testClear,
+		}},
+		{"position", `x="3" y="-4"`, testClear, map[image.Point]color.RGBA{
+			{13, 12}: testRed, {16, 13}: testWhite, {10, 8}: testClear,
+		}},
+		{"flip x", `x="0" y="0" scale_x="-1"`, testClear, map[image.Point]color.RGBA{
+			{9, 8}: testRed, {6, 8}: testBlue, {9, 9}: testGreen, {6, 9}: testWhite, {10, 8}: testClear,
+		}},
+		{"flip y", `x="0" y="0" scale_y="-1"`, testClear, map[image.Point]color.RGBA{
+			{10, 11}: testRed, {13, 11}: testBlue, {10, 10}: testGreen, {13, 10}: testWhite, {10, 9}: testClear,
+		}},
+		{"rotation", `x="0" y="0" angle="90"`, testClear, map[image.Point]color.RGBA{
+			{8, 9}: testRed, {8, 6}: testBlue, {9, 9}: testGreen, {9, 6}: testWhite, {10, 9}: testClear,
+		}},
+		{"scale", `x="0" y="0" scale_x="2" scale_y="2"`, testClear, map[image.Point]color.RGBA{
+			{10, 6}: testRed, {17, 6}: testBlue, {10, 9}: testGreen, {17, 9}: testWhite, {18, 9}: testClear,
+		}},
+		{"alpha on transparent", `x="0" y="0" a="0.5"`, testClear, map[image.Point]color.RGBA{
+			{10, 8}: {128, 0, 0, 128}, {13, 9}: {128, 128, 128, 128},
+		}},
+		{"alpha on opaque", `x="0" y="0" a="0.5"`, color.RGBA{0, 0, 255, 255}, map[image.Point]color.RGBA{
+			{10, 8}: {128, 0, 128, 255}, {13, 9}: {128, 128, 255, 255}, {9, 9}: {0, 0, 255, 255},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model := loadTestModel(t, fmt.Sprintf(softwareRendererTestSCML, test.object))
+			p := MakeEntityPlayer(model.Entities[0])
+			p.Update(0)
+
+			renderer := MakeSoftwareRenderer(model, image.NewRGBA(image.Rect(0, 0, 20, 20)))
+			renderer.SetImage(0, makeTestQuadImage())
+			renderer.Clear(test.background)
+			p.Draw(renderer)
+			if err := renderer.Err(); err != nil {
+				t.Fatal(err)
+			}
+			for point, expected := range test.pixels {
+				if actual := renderer.Target.RGBAAt(point.X, point.Y); actual != expected {
+					t.Errorf("pixel %v: expected %v, got %v", point, expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package spriter
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type SVGOptions struct {
+	// Prepended to the names of the files to build the links of the images
+	ImagePrefix string
+	// Bones, boxes, points and pivots to draw on top of the sprites
+	Debug  DebugFlags
+	Margin float64
+}
+
+func MakeSVGOptions() *SVGOptions {
+	return &SVGOptions{
+		Debug:  DebugBones | DebugBoxes | DebugPoints,
+		Margin: 10,
+	}
+}
+
+// WriteSVG writes the current pose as an SVG document. Each sprite is an <image> with its transformation,
+// bones are paths and boxes are polygons. The ids of the elements are derived from the names of the timelines.
+// A nil options uses MakeSVGOptions.
+func (p *EntityPlayer) WriteSVG(w io.Writer, options *SVGOptions) error {
+	if options == nil {
+		options = MakeSVGOptions()
+	}
+	commands := p.GetDrawCommands()
+	primitives := p.GetDebugPrimitives(options.Debug)
+
+	bounds := MakeEmptyRect()
+	for i := range commands {
+		corners := commands[i].Corners()
+		for j := range corners {
+			bounds.AddPoint(corners[j].X(), corners[j].Y())
+		}
+	}
+	for i := range primitives {
+		for j := range primitives[i].Points {
+			bounds.AddPoint(primitives[i].Points[j].X(), primitives[i].Points[j].Y())
+		}
+	}
+	if bounds.IsEmpty() {
+		bounds.AddPoint(0, 0)
+	}
+	minX := bounds.MinX - options.Margin
+	minY := -bounds.MaxY - options.Margin
+	width := bounds.Width() + 2*options.Margin
+	height := bounds.Height() + 2*options.Margin
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" viewBox=\"%s %s %s %s\" width=\"%s\" height=\"%s\">\n",
+		svgNumber(minX), svgNumber(minY), svgNumber(width), svgNumber(height), svgNumber(width), svgNumber(height))
+	// Spriter's Y axis points up
+	fmt.Fprintf(out, "<g id=\"%s\" transform=\"scale(1,-1)\">\n", svgEscape(p.entity.Name))
+
+	ids := make(map[string]bool)
+	fmt.Fprintf(out, "<g id=\"sprites\">\n")
+	for i := range commands {
+		c := &commands[i]
+		m := c.Transform
+		href := path.Join(options.ImagePrefix, c.File.Name)
+		fmt.Fprintf(out, "<image id=\"%s\" xlink:href=\"%s\" href=\"%s\" width=\"%d\" height=\"%d\" transform=\"matrix(%s,%s,%s,%s,%s,%s)\" opacity=\"%s\"/>\n",
+			makeSVGId(ids, "sprite", c.Name), svgEscape(href), svgEscape(href), c.File.Width, c.File.Height,
+			svgNumber(m[0]), svgNumber(m[1]), svgNumber(m[2]), svgNumber(m[3]), svgNumber(m[4]), svgNumber(m[5]),
+			svgNumber(c.Alpha*c.Tint.A))
+	}
+	fmt.Fprintf(out, "</g>\n")
+
+	if len(primitives) > 0 {
+		fmt.Fprintf(out, "<g id=\"debug\" fill=\"none\" stroke-width=\"1\" vector-effect=\"non-scaling-stroke\">\n")
+		for i := range primitives {
+			primitive := &primitives[i]
+			color := svgColor(primitive.Color)
+			switch primitive.Element {
+			case DebugBones:
+				fmt.Fprintf(out, "<path id=\"%s\" d=\"%s\" stroke=\"%s\"/>\n",
+					makeSVGId(ids, "bone", primitive.Name), svgPath(primitive.Points, primitive.Type == DebugPolygon), color)
+			case DebugBoxes:
+				fmt.Fprintf(out, "<polygon id=\"%s\" points=\"%s\" stroke=\"%s\"/>\n",
+					makeSVGId(ids, "box", primitive.Name), svgPoints(primitive.Points), color)
+			case DebugPoints:
+				fmt.Fprintf(out, "<path id=\"%s\" d=\"%s\" stroke=\"%s\"/>\n",
+					makeSVGId(ids, "point", primitive.Name), svgPath(primitive.Points, primitive.Type == DebugPolygon), color)
+			case DebugPivots:
+				fmt.Fprintf(out, "<path id=\"%s\" d=\"%s\" stroke=\"%s\"/>\n",
+					makeSVGId(ids, "pivot", primitive.Name), svgPath(primitive.Points, false), color)
+			}
+		}
+		fmt.Fprintf(out, "</g>\n")
+	}
+	fmt.Fprintf(out, "</g>\n</svg>\n")
+	return out.Flush()
+}
+
+// SaveSVG writes the current pose to an SVG file
+func (p *EntityPlayer) SaveSVG(fileName string, options *SVGOptions) error {
+	return writeFile(fileName, func(w io.Writer) error {
+		return p.WriteSVG(w, options)
+	})
+}
+
+// makeSVGId builds a valid and unique id from the name of a timeline
+func makeSVGId(used map[string]bool, prefix string, name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	id := prefix + "-" + sanitized
+	unique := id
+	for i := 2; used[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// svgNumber rounds the value to 4 decimals, so that documents are stable and easy to diff
+func svgNumber(value float64) string {
+	value = math.Round(value*10000) / 10000
+	if value == 0 {
+		// No negative zeros
+		value = 0
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func svgEscape(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+func svgColor(c Color) string {
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", int(c.R*255), int(c.G*255), int(c.B*255), svgNumber(c.A))
+}
+
+func svgPoints(points []Point) string {
+	values := make([]string, len(points))
+	for i := range points {
+		values[i] = svgNumber(points[i].X()) + "," + svgNumber(points[i].Y())
+	}
+	return strings.Join(values, " ")
+}
+
+func svgPath(points []Point, closed bool) string {
+	var b strings.Builder
+	for i := range points {
+		if i == 0 {
+			b.WriteString("M")
+		} else {
+			b.WriteString(" L")
+		}
+		b.WriteString(svgNumber(points[i].X()) + " " + svgNumber(points[i].Y()))
+	}
+	if closed {
+		b.WriteString(" Z")
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package spriter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestWriteSVGNilOptions(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.Update(0)
+	var buffer bytes.Buffer
+	if err := p.WriteSVG(&buffer, nil); err != nil {
+		t.Fatal(err)
+	}
+	decoder := xml.NewDecoder(&buffer)
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package spriter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExternalFileIndex returns the file index identifying a texture which is not in the model, e.g. one loaded at
+// runtime by an equipment system. External indices are negative and never collide with the files of the model
+// or with -1, which hides an object.
+func ExternalFileIndex(textureId int) int {
+	return -2 - textureId
+}
+
+// IsExternalFileIndex tells if a file index, e.g. the one of a DrawCommand, identifies an external texture
+func IsExternalFileIndex(fileIndex int) bool {
+	return fileIndex <= -2
+}
+
+// GetExternalTextureId returns the id given to ExternalFileIndex
+func GetExternalTextureId(fileIndex int) int {
+	return -2 - fileIndex
+}
+
+// spriteSwap replaces the file drawn by an object
+type spriteSwap struct {
+	fileIndex int
+	// Size of an external texture
+	file *File
+}
+
+// SwapObjectSprite makes the object with the given name draw another file of the model.
+// Swaps are applied after the character maps and win over them.
+func (p *EntityPlayer) SwapObjectSprite(objectName string, targetFolder int, targetFile int) *EntityPlayer {
+	return p.setObjectSwap(objectName, spriteSwap{fileIndex: FolderAndFileToFileIndex(targetFolder, targetFile)})
+}
+
+// HideObjectSprite makes the object with the given name draw nothing, until its swap is removed
+func (p *EntityPlayer) HideObjectSprite(objectName string) *EntityPlayer {
+	return p.setObjectSwap(objectName, spriteSwap{fileIndex: -1})
+}
+
+// SwapObjectSpriteExternal makes the object with the given name draw an external texture. `file` gives its size,
+// the pivot is the one of the object like with character maps. Draw commands identify it with ExternalFileIndex(textureId).
+func (p *EntityPlayer) SwapObjectSpriteExternal(objectName string, textureId int, file *File) error {
+	if err := checkExternalSwap(textureId, file); err != nil {
+		return err
+	}
+	p.setObjectSwap(objectName, spriteSwap{fileIndex: ExternalFileIndex(textureId), file: file})
+	return nil
+}
+
+// SwapFileSprite makes all the objects drawing a file, after the character maps have been applied, draw another
+// file of the model. Swaps by object name win over the ones by file.
+func (p *EntityPlayer) SwapFileSprite(folder int, file int, targetFolder int, targetFile int) *EntityPlayer {
+	return p.setFileSwap(folder, file, spriteSwap{fileIndex: FolderAndFileToFileIndex(targetFolder, targetFile)})
+}
+
+// HideFileSprite makes all the objects drawing a file, after the character maps have been applied, draw nothing
+func (p *EntityPlayer) HideFileSprite(folder int, file int) *EntityPlayer {
+	return p.setFileSwap(folder, file, spriteSwap{fileIndex: -1})
+}
+
+// SwapFileSpriteExternal works like SwapFileSprite, with an external texture
+func (p *EntityPlayer) SwapFileSpriteExternal(folder int, file int, textureId int, external *File) error {
+	if err := checkExternalSwap(textureId, external); err != nil {
+		return err
+	}
+	p.setFileSwap(folder, file, spriteSwap{fileIndex: ExternalFileIndex(textureId), file: external})
+	return nil
+}
+
+func checkExternalSwap(textureId int, file *File) error {
+	if file == nil {
+		return errors.New("an external texture needs a file with its size")
+	}
+	if textureId < 0 {
+		return fmt.Errorf("invalid texture id %d", textureId)
+	}
+	return nil
+}
+
+func (p *EntityPlayer) setObjectSwap(objectName string, swap spriteSwap) *EntityPlayer {
+	p.objectSwaps[objectName] = swap
+	p.animationBounds = nil
+	return p
+}
+
+func (p *EntityPlayer) setFileSwap(folder int, file int, swap spriteSwap) *EntityPlayer {
+	p.fileSwaps[FolderAndFileToFileIndex(folder, file)] = swap
+	p.animationBounds = nil
+	return p
+}
+
+func (p *EntityPlayer) RemoveObjectSwap(objectName string) *EntityPlayer {
+	delete(p.objectSwaps, objectName)
+	p.animationBounds = nil
+	return p
+}
+
+func (p *EntityPlayer) RemoveFileSwap(folder int, file int) *EntityPlayer {
+	delete(p.fileSwaps, FolderAndFileToFileIndex(folder, file))
+	p.animationBounds = nil
+	return p
+}
+
+func (p *EntityPlayer) ClearSpriteSwaps() *EntityPlayer {
+	p.objectSwaps = make(map[string]spriteSwap)
+	p.fileSwaps = make(map[int]spriteSwap)
+	p.animationBounds = nil
+	return p
+}
+
+// getSpriteSwap returns the swap of an object, given the file it draws after the character maps
+func (p *EntityPlayer) getSpriteSwap(object *TimelineKeyObject, fileIndex int) (spriteSwap, bool) {
+	if len(p.objectSwaps) > 0 {
+		key := p.objToTimeline[object]
+		if key != nil && key.Id < len(p.animation.Timelines) {
+			if swap, ok := p.objectSwaps[p.animation.Timelines[key.Id].Name]; ok {
+				return swap, true
+			}
+		}
+	}
+	swap, ok := p.fileSwaps[fileIndex]
+	return swap, ok
+}
+
+func (p *EntityPlayer) copySpriteSwaps(from *EntityPlayer) {
+	for name, swap := range from.objectSwaps {
+		p.objectSwaps[name] = swap
+	}
+	for fileIndex, swap := range from.fileSwaps {
+		p.fileSwaps[fileIndex] = swap
+	}
+}
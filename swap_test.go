@@ -0,0 +1,123 @@
+package spriter
+
+import (
+	"image"
+	"testing"
+)
+
+// getDrawnFiles returns the index of the file drawn by each object of the current key
+func getDrawnFiles(p *EntityPlayer) map[string]int {
+	files := make(map[string]int)
+	for _, objectFile := range p.GetObjectFiles() {
+		files[objectFile.Name] = objectFile.FileIndex
+	}
+	return files
+}
+
+func TestSpriteSwaps(t *testing.T) {
+	body, arm, sword := FolderAndFileToFileIndex(0, 0), FolderAndFileToFileIndex(0, 1), FolderAndFileToFileIndex(0, 2)
+	axe := &File{Name: "axe", Width: 20, Height: 20}
+	tests := []struct {
+		name     string
+		setup    func(p *EntityPlayer)
+		expected map[string]int
+	}{
+		{"none", func(p *EntityPlayer) {}, map[string]int{"body": body, "arm": arm}},
+		{"object", func(p *EntityPlayer) {
+			p.SwapObjectSprite("arm", 0, 2)
+		}, map[string]int{"body": body, "arm": sword}},
+		{"file", func(p *EntityPlayer) {
+			p.SwapFileSprite(0, 0, 0, 1)
+		}, map[string]int{"body": arm, "arm": arm}},
+		{"file after the character maps", func(p *EntityPlayer) {
+			p.EnableCharacterMap("armed")
+			p.SwapFileSprite(0, 1, 0, 0)
+			p.SwapFileSprite(0, 2, 0, 0)
+		}, map[string]int{"body": body, "arm": body}},
+		{"object wins over file and character maps", func(p *EntityPlayer) {
+			p.EnableCharacterMap("armed")
+			p.SwapFileSprite(0, 2, 0, 0)
+			p.SwapObjectSprite("arm", 0, 1)
+		}, map[string]int{"body": body, "arm": arm}},
+		{"hide object", func(p *EntityPlayer) {
+			p.HideObjectSprite("body")
+		}, map[string]int{"body": -1, "arm": arm}},
+		{"hide file", func(p *EntityPlayer) {
+			p.HideFileSprite(0, 1)
+		}, map[string]int{"body": body, "arm": -1}},
+		{"external", func(p *EntityPlayer) {
+			if err := p.SwapObjectSpriteExternal("arm", 7, axe); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SwapFileSpriteExternal(0, 0, 0, axe); err != nil {
+				t.Fatal(err)
+			}
+		}, map[string]int{"body": ExternalFileIndex(0), "arm": ExternalFileIndex(7)}},
+		{"removed", func(p *EntityPlayer) {
+			p.SwapObjectSprite("arm", 0, 2).HideFileSprite(0, 0)
+			p.RemoveObjectSwap("arm").RemoveFileSwap(0, 0)
+		}, map[string]int{"body": body, "arm": arm}},
+		{"cleared", func(p *EntityPlayer) {
+			p.SwapObjectSprite("arm", 0, 2).HideFileSprite(0, 0).ClearSpriteSwaps()
+		}, map[string]int{"body": body, "arm": arm}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+			p.Update(0)
+			test.setup(p)
+			files := getDrawnFiles(p)
+			for name, expected := range test.expected {
+				if files[name] != expected {
+					t.Errorf("%s: expected file %d, got %d", name, expected, files[name])
+				}
+			}
+		})
+	}
+}
+
+func TestExternalSpriteSwap(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.Update(0)
+	if err := p.SwapObjectSpriteExternal("arm", 1, nil); err == nil {
+		t.Error("a nil file must be rejected")
+	}
+	if err := p.SwapFileSpriteExternal(0, 1, 1, nil); err == nil {
+		t.Error("a nil file must be rejected")
+	}
+	if err := p.SwapObjectSpriteExternal("arm", -1, &File{}); err == nil {
+		t.Error("a negative texture id must be rejected")
+	}
+	if files := getDrawnFiles(p); files["arm"] != FolderAndFileToFileIndex(0, 1) {
+		t.Fatalf("a rejected swap must not change the object, got file %d", files["arm"])
+	}
+
+	axe := &File{Name: "axe", Width: 20, Height: 20}
+	if err := p.SwapObjectSpriteExternal("arm", 3, axe); err != nil {
+		t.Fatal(err)
+	}
+	var command *DrawCommand
+	commands := p.GetDrawCommands()
+	for i := range commands {
+		if commands[i].Name == "arm" {
+			command = &commands[i]
+		}
+	}
+	if command == nil || command.File != axe || !IsExternalFileIndex(command.FileIndex) || GetExternalTextureId(command.FileIndex) != 3 {
+		t.Fatalf("expected a command drawing the external texture 3, got %+v", command)
+	}
+
+	// The software renderer doesn't look for external textures in the folder of the model
+	renderer := MakeSoftwareRenderer(p.entity.model, image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	if img := renderer.getImage(command); img != nil {
+		t.Fatal("no image expected for an external texture which has not been set")
+	}
+	if err := renderer.Err(); err != nil {
+		t.Fatalf("external textures are not loaded: %v", err)
+	}
+	texture := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	renderer.SetImage(command.FileIndex, texture)
+	if img := renderer.getImage(command); img != texture {
+		t.Fatal("expected the image set for the external texture")
+	}
+}
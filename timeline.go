@@ -72,6 +72,7 @@ type TimelineKeyObject struct {
 	XMLPivotY *float64 `xml:"pivot_y,attr"`
 	XMLScaleX *float64 `xml:"scale_x,attr"`
 	XMLScaleY *float64 `xml:"scale_y,attr"`
+	XMLAlpha  *float64 `xml:"a,attr"`
 }
 
 func (b *TimelineKeyObject) String() string {
@@ -86,6 +87,7 @@ func MakeTimelineKeyObject() *TimelineKeyObject {
 		Position:   MakePoint(0, 0),
 		Pivot:      MakePoint(0, 1),
 		Scale:      MakePoint(1, 1),
+		Alpha:      1,
 		objectType: "object",
 	}
 }
@@ -95,6 +97,7 @@ func MakeTimelineKeyBone() *TimelineKeyObject {
 		Position:   MakePoint(0, 0),
 		Pivot:      MakePoint(0, 1),
 		Scale:      MakePoint(1, 1),
+		Alpha:      1,
 		objectType: "bone",
 	}
 }
@@ -107,6 +110,7 @@ func (b *TimelineKeyObject) setWithBone(bone *TimelineKeyObject) {
 	b.Position.Set(bone.Position)
 	b.Scale.Set(bone.Scale)
 	b.Angle = bone.Angle
+	b.Alpha = bone.Alpha
 	b.Pivot.Set(bone.Pivot)
 	b.objectType = bone.objectType
 	b.File = bone.File
@@ -0,0 +1,129 @@
+package spriter
+
+import (
+	"errors"
+	"math"
+)
+
+// Number of float32 values per vertex: x, y, u, v, color
+const VertexStride = 5
+
+// ErrVertexBufferFull is returned when a quad doesn't fit in the 16 bits indices of a VertexBuffer
+var ErrVertexBufferFull = errors.New("vertex buffer: the 16 bits indices are full")
+
+// TextureRegion is the area of a texture containing the image of a file, e.g. in an atlas.
+// UVs are normalized, (0,0) being the top-left corner of the texture.
+type TextureRegion struct {
+	Texture int
+	U0      float32
+	V0      float32
+	U1      float32
+	V1      float32
+}
+
+// VertexBatch is a range of indices that can be drawn with a single call, using the same texture
+type VertexBatch struct {
+	Texture    int
+	IndexStart int
+	IndexCount int
+}
+
+// VertexBuffer collects the quads of the sprites of one or more players, ready to be uploaded to the GPU.
+// Each vertex is made of VertexStride float32 values: the position in world coordinates, the UVs, and the
+// color packed as 4 bytes (R, G, B, A in memory order) reinterpreted as a float32.
+// The slices are reused by Reset, so that no memory is allocated once they are big enough.
+type VertexBuffer struct {
+	Vertices []float32
+	Indices  []uint16
+	Batches  []VertexBatch
+	// Optional: where the image of each file is. Files without a region use their whole texture,
+	// identified by the file index.
+	Regions map[int]TextureRegion
+
+	commands []DrawCommand
+}
+
+// MakeVertexBuffer creates a buffer using the given slices as storage
+func MakeVertexBuffer(vertices []float32, indices []uint16) *VertexBuffer {
+	return &VertexBuffer{
+		Vertices: vertices[:0],
+		Indices:  indices[:0],
+		Batches:  make([]VertexBatch, 0),
+	}
+}
+
+func (b *VertexBuffer) Reset() {
+	b.Vertices = b.Vertices[:0]
+	b.Indices = b.Indices[:0]
+	b.Batches = b.Batches[:0]
+}
+
+// NumQuads returns the number of sprites in the buffer
+func (b *VertexBuffer) NumQuads() int {
+	return len(b.Vertices) / (4 * VertexStride)
+}
+
+func (b *VertexBuffer) getRegion(fileIndex int) TextureRegion {
+	if region, ok := b.Regions[fileIndex]; ok {
+		return region
+	}
+	return TextureRegion{Texture: fileIndex, U0: 0, V0: 0, U1: 1, V1: 1}
+}
+
+// PackColor packs a color in a float32, with the bytes in R, G, B, A order in memory
+func PackColor(c Color) float32 {
+	r := uint32(math.Round(math.Max(0, math.Min(1, c.R)) * 255))
+	g := uint32(math.Round(math.Max(0, math.Min(1, c.G)) * 255))
+	bl := uint32(math.Round(math.Max(0, math.Min(1, c.B)) * 255))
+	a := uint32(math.Round(math.Max(0, math.Min(1, c.A)) * 255))
+	return math.Float32frombits(a<<24 | bl<<16 | g<<8 | r)
+}
+
+// AddQuad appends a quad for a draw command. ErrVertexBufferFull is returned, and nothing is added, when the
+// buffer already holds the maximum number of quads.
+func (b *VertexBuffer) AddQuad(command *DrawCommand) error {
+	if len(b.Vertices)/VertexStride+4 > math.MaxUint16+1 {
+		return ErrVertexBufferFull
+	}
+	region := b.getRegion(command.FileIndex)
+	tint := command.Tint
+	packedColor := PackColor(MakeColor(tint.R, tint.G, tint.B, tint.A*command.Alpha))
+	corners := command.Corners()
+	uvs := [4][2]float32{
+		{region.U0, region.V0},
+		{region.U1, region.V0},
+		{region.U1, region.V1},
+		{region.U0, region.V1},
+	}
+
+	base := uint16(len(b.Vertices) / VertexStride)
+	for i := range corners {
+		b.Vertices = append(b.Vertices, float32(corners[i].X()), float32(corners[i].Y()), uvs[i][0], uvs[i][1], packedColor)
+	}
+	b.Indices = append(b.Indices, base, base+1, base+2, base, base+2, base+3)
+
+	last := len(b.Batches) - 1
+	if last >= 0 && b.Batches[last].Texture == region.Texture {
+		b.Batches[last].IndexCount += 6
+	} else {
+		b.Batches = append(b.Batches, VertexBatch{
+			Texture:    region.Texture,
+			IndexStart: len(b.Indices) - 6,
+			IndexCount: 6,
+		})
+	}
+	return nil
+}
+
+// FillVertexBuffer appends the quads of the visible sprites of the current pose, in z order, and returns how
+// many have been added. Indices are 16 bits: a buffer can hold up to 16384 quads. When it's full the remaining
+// sprites are dropped and ErrVertexBufferFull is returned: the buffer must be drawn and reset, then filled again.
+func (p *EntityPlayer) FillVertexBuffer(buffer *VertexBuffer) (int, error) {
+	buffer.commands = p.AppendDrawCommands(buffer.commands[:0])
+	for i := range buffer.commands {
+		if err := buffer.AddQuad(&buffer.commands[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(buffer.commands), nil
+}
@@ -0,0 +1,37 @@
+package spriter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFillVertexBuffer(t *testing.T) {
+	p := MakeEntityPlayer(loadTestModel(t, testHeroSCML).Entities[0])
+	p.Update(0)
+	sprites := len(p.GetDrawCommands())
+	if sprites < 2 {
+		t.Fatalf("expected at least 2 sprites, got %d", sprites)
+	}
+
+	buffer := MakeVertexBuffer(nil, nil)
+	quads, err := p.FillVertexBuffer(buffer)
+	if err != nil || quads != sprites || buffer.NumQuads() != sprites || len(buffer.Indices) != 6*sprites {
+		t.Fatalf("expected %d quads, got %d (%d in the buffer), error %v", sprites, quads, buffer.NumQuads(), err)
+	}
+
+	// Room for a single quad
+	buffer.Reset()
+	maxQuads := (math.MaxUint16 + 1) / 4
+	buffer.Vertices = append(buffer.Vertices, make([]float32, (maxQuads-1)*4*VertexStride)...)
+	quads, err = p.FillVertexBuffer(buffer)
+	if err != ErrVertexBufferFull || quads != 1 || buffer.NumQuads() != maxQuads {
+		t.Fatalf("expected 1 quad and ErrVertexBufferFull, got %d quads (%d in the buffer), error %v", quads, buffer.NumQuads(), err)
+	}
+	if err := buffer.AddQuad(&p.GetDrawCommands()[0]); err != ErrVertexBufferFull || buffer.NumQuads() != maxQuads {
+		t.Fatal("a full buffer must not accept quads")
+	}
+	// The last index is still valid
+	if last := buffer.Indices[len(buffer.Indices)-1]; int(last) != maxQuads*4-1 {
+		t.Fatalf("expected the last index to be %d, got %d", maxQuads*4-1, last)
+	}
+}